@@ -0,0 +1,92 @@
+package timezoneLookup
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+)
+
+// newAntimeridianCache returns a Timezonecache with Pacific/Fiji across the
+// antimeridian, Asia/Anadyr east of it up to 180°, Pacific/Niue around 0° and
+// Pacific/Samoa apart from them.
+func newAntimeridianCache(t *testing.T) *Timezonecache {
+	t.Helper()
+	const timezones = `{"type":"FeatureCollection","features":[
+		{"type":"Feature","properties":{"tzid":"Pacific/Fiji"},
+		"geometry":{"type":"Polygon","coordinates":[[[178,-18],[-179,-18],[-179,-16],[178,-16],[178,-18]]]}},
+		{"type":"Feature","properties":{"tzid":"Asia/Anadyr"},
+		"geometry":{"type":"Polygon","coordinates":[[[179,60],[180,60],[180,61],[179,61],[179,60]]]}},
+		{"type":"Feature","properties":{"tzid":"Pacific/Niue"},
+		"geometry":{"type":"Polygon","coordinates":[[[-1,-1],[1,-1],[1,1],[-1,1],[-1,-1]]]}}]}`
+	tzc := new(Timezonecache)
+	if err := ImportGeoJSON(strings.NewReader(timezones), func(tz Timezone) error {
+		tzc.AddTimezone(tz)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	tzc.AddTimezone(Timezone{Name: "Pacific/Samoa", Polygons: []geo.Polygon{square(-15, -173, 2)}})
+	return tzc
+}
+
+func TestSearchAntimeridian(t *testing.T) {
+	tests := []struct {
+		lat, lng float64
+		name     string
+	}{
+		{-17, 179.99, "Pacific/Fiji"},
+		{-17, -179.99, "Pacific/Fiji"},
+		{-17, 180, "Pacific/Fiji"},
+		{-17, -180, "Pacific/Fiji"},
+		{-17, 178.5, "Pacific/Fiji"},
+		{-17, -179.5, "Pacific/Fiji"},
+		{-17, 0, ""},
+		{-17, 177.99, ""},
+		{-17, -178.99, ""},
+		{60.5, 179.99, "Asia/Anadyr"},
+		{60.5, 180, "Asia/Anadyr"},
+		{60.5, -179.99, ""},
+		{0, 0, "Pacific/Niue"},
+		{0, 179.99, ""},
+		{0, -180, ""},
+	}
+	mem := newAntimeridianCache(t)
+	for _, tzc := range []*Timezonecache{mem, loadFile(t, saveFile(t, mem))} {
+		for _, tt := range tests {
+			expectSearch(t, tzc, tt.lat, tt.lng, tt.name)
+		}
+	}
+}
+
+func TestNearestFallbackAntimeridian(t *testing.T) {
+	tests := []struct {
+		lat, lng float64
+		name     string
+	}{
+		{60.5, -179.99, "Asia/Anadyr"}, // 0.01° across the antimeridian
+		{60.5, -180, "Asia/Anadyr"},
+		{60.5, 179.95, "Asia/Anadyr"},
+		{-17, -178.99, "Pacific/Fiji"},
+		{-17, 177.99, "Pacific/Fiji"},
+		{-14, -173.01, "Pacific/Samoa"},
+	}
+	mem := newAntimeridianCache(t)
+	for _, tzc := range []*Timezonecache{mem, loadFile(t, saveFile(t, mem))} {
+		tzc.SetNearestFallback(10000)
+		for _, tt := range tests {
+			res, err := tzc.Search(tt.lat, tt.lng)
+			if err != nil {
+				t.Fatalf("Search(%v, %v): %v", tt.lat, tt.lng, err)
+			}
+			if res.Name != tt.name || res.Source == SourcePolygon && res.Distance != 0 ||
+				res.Source == SourceNearest && (!res.Approximate || res.Distance > 10000) {
+				t.Fatalf("Search(%v, %v) = %q %v at %vm, want %q", tt.lat, tt.lng, res.Name, res.Source, res.Distance, tt.name)
+			}
+		}
+		// more than 10km from Asia/Anadyr across the antimeridian
+		if res, err := tzc.Search(60.5, -179.5); err != ErrNoTimezone || res.ZoneID != NoZone {
+			t.Fatalf("Search(60.5, -179.5) = %q, %v, want %v", res.Name, err, ErrNoTimezone)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package timezoneLookup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+)
+
+const (
+	// batchSize is the number of points a worker searches at a time
+	batchSize = 256
+)
+
+// StreamResult is a Result of SearchStream
+type StreamResult struct {
+	Result
+	Err error
+}
+
+// SetWorkers sets the number of goroutines used by SearchBatch and SearchStream.
+// Defaults to runtime.GOMAXPROCS(0) when n < 1.
+func (tzc *Timezonecache) SetWorkers(n int) {
+	tzc.mu.Lock()
+	tzc.workers = n
+	tzc.mu.Unlock()
+}
+
+func (tzc *Timezonecache) numWorkers() int {
+	if tzc.workers > 0 {
+		return tzc.workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// SearchBatch searches the timezones of points and stores the results in out
+// in the same order. out must be at least as long as points. Searches are run
+// in parallel by SetWorkers goroutines and Result.Elapsed is not recorded.
+//
+// Every point is searched. The error of the point with the lowest index is returned.
+func (tzc *Timezonecache) SearchBatch(points []geo.LatLng, out []Result) error {
+	return tzc.SearchBatchContext(context.Background(), points, out)
+}
+
+// SearchBatchContext is SearchBatch with a context. When ctx is done the workers stop
+// at the next RTree node or polygon and ctx.Err() is returned. The results of points
+// that were not searched are left unchanged.
+func (tzc *Timezonecache) SearchBatchContext(ctx context.Context, points []geo.LatLng, out []Result) error {
+	if len(out) < len(points) {
+		return errors.New("error out is shorter than points")
+	}
+	if err := tzc.rlock(); err != nil {
+		return err
+	}
+	i, err := tzc.searchBatch(ctx, points, out, nil)
+	tzc.mu.RUnlock()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("point %d: %w", i, err)
+	}
+	return nil
+}
+
+// searchBatch searches points in parallel and stores the results in out and
+// the errors in errs when errs is not nil. Returns the error of the point with
+// the lowest index. The Timezonecache must be locked for searching.
+func (tzc *Timezonecache) searchBatch(ctx context.Context, points []geo.LatLng, out []Result, errs []error) (int, error) {
+	workers := tzc.numWorkers()
+	if max := (len(points) + batchSize - 1) / batchSize; workers > max {
+		workers = max
+	}
+
+	var next int64
+	var mu sync.Mutex
+	errIndex, errFirst := len(points), error(nil)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			s := searcher{tzc: tzc, ctx: ctx}
+			for ctx.Err() == nil {
+				start := int(atomic.AddInt64(&next, batchSize)) - batchSize
+				if start >= len(points) {
+					return
+				}
+				end := start + batchSize
+				if end > len(points) {
+					end = len(points)
+				}
+				for i := start; i < end; i++ {
+					var err error
+					out[i], err = s.search(points[i])
+					if errs != nil {
+						errs[i] = err
+					}
+					if err != nil {
+						mu.Lock()
+						if i < errIndex {
+							errIndex, errFirst = i, err
+						}
+						mu.Unlock()
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return errIndex, errFirst
+}
+
+// SearchStream searches the timezones of the points received from in and sends the
+// results in the same order to the returned channel. The returned channel is closed
+// once in is closed and all of its points have been searched. Points received
+// after Close return ErrClosed.
+func (tzc *Timezonecache) SearchStream(in <-chan geo.LatLng) <-chan StreamResult {
+	out := make(chan StreamResult, batchSize)
+	go func() {
+		defer close(out)
+		tzc.mu.RLock()
+		points := make([]geo.LatLng, 0, batchSize*tzc.numWorkers())
+		tzc.mu.RUnlock()
+		results := make([]Result, cap(points))
+		errs := make([]error, cap(points))
+		for ll := range in {
+			// collect the points that are ready without blocking
+			points = append(points[:0], ll)
+		collect:
+			for len(points) < cap(points) {
+				select {
+				case ll, ok := <-in:
+					if !ok {
+						break collect
+					}
+					points = append(points, ll)
+				default:
+					break collect
+				}
+			}
+			if err := tzc.rlock(); err != nil {
+				for i := range points {
+					results[i], errs[i] = Result{ZoneID: NoZone}, err
+				}
+			} else {
+				tzc.searchBatch(context.Background(), points, results, errs)
+				tzc.mu.RUnlock()
+			}
+			for i := range points {
+				out <- StreamResult{Result: results[i], Err: errs[i]}
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,122 @@
+package timezoneLookup
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+)
+
+// TestCloseConcurrent runs Search, SearchBatch and SearchStream on memory mapped
+// timezone data while it is closed. Run with -race.
+func TestCloseConcurrent(t *testing.T) {
+	tzc := loadFile(t, saveFile(t, newTestCache()))
+	points := []geo.LatLng{geo.NewLatLng(5, 5), geo.NewLatLng(5, 15), geo.NewLatLng(22, 22)}
+	want := []string{"A", "B", "C"}
+
+	var wg sync.WaitGroup
+	var searches int64
+	started := make(chan struct{})
+	var once sync.Once
+	errc := make(chan error, 16)
+	check := func(name string, res Result, err error, i int) bool {
+		if errors.Is(err, ErrClosed) {
+			return false
+		}
+		if err != nil {
+			errc <- err
+			return false
+		}
+		if res.Name != want[i] {
+			errc <- errors.New(name + " returned " + res.Name + " instead of " + want[i])
+			return false
+		}
+		if atomic.AddInt64(&searches, 1) > 100 {
+			once.Do(func() { close(started) })
+		}
+		return true
+	}
+	for w := 0; w < 4; w++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				ll := points[i%len(points)]
+				res, err := tzc.Search(ll.Lat, ll.Lng)
+				if !check("Search", res, err, i%len(points)) {
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			out := make([]Result, len(points))
+			for {
+				err := tzc.SearchBatch(points, out)
+				for i := range points {
+					if !check("SearchBatch", out[i], err, i) {
+						return
+					}
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			in := make(chan geo.LatLng)
+			out := tzc.SearchStream(in)
+			defer func() {
+				close(in)
+				for range out {
+				}
+			}()
+			for i := 0; ; i++ {
+				in <- points[i%len(points)]
+				r := <-out
+				if !check("SearchStream", r.Result, r.Err, i%len(points)) {
+					return
+				}
+			}
+		}()
+	}
+	<-started
+	if err := tzc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	close(errc)
+	for err := range errc {
+		t.Error(err)
+	}
+
+	if _, err := tzc.Search(5, 5); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Search after Close: %v", err)
+	}
+	if err := tzc.SearchBatch(points, make([]Result, len(points))); !errors.Is(err, ErrClosed) {
+		t.Fatalf("SearchBatch after Close: %v", err)
+	}
+	if err := tzc.Close(); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Close after Close: %v", err)
+	}
+}
+
+func TestClosedSetters(t *testing.T) {
+	tzc := newTestCache()
+	if err := tzc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	tzc.AddTimezone(Timezone{Name: "D", Polygons: []geo.Polygon{square(40, 40, 5)}})
+	if info := tzc.Info(); info.Polygons != 0 || info.Zones != 0 {
+		t.Fatalf("AddTimezone after Close added %d polygons and %d zones", info.Polygons, info.Zones)
+	}
+	if err := tzc.SetPrecision(geo.MicroDegrees); !errors.Is(err, ErrClosed) {
+		t.Fatalf("SetPrecision after Close: %v", err)
+	}
+	if err := tzc.SetCodec(geo.Delta); !errors.Is(err, ErrClosed) {
+		t.Fatalf("SetCodec after Close: %v", err)
+	}
+	if err := tzc.Save(t.TempDir() + "/timezone.data"); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Save after Close: %v", err)
+	}
+}
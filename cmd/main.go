@@ -5,25 +5,42 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
 	"syscall"
 	"time"
 
 	timezone "github.com/evanoberholster/timezoneLookup/v2"
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+	"github.com/evanoberholster/timezoneLookup/v2/server"
 )
 
 var (
-	// TODO: benchmark     = flag.Bool("benchmark", false, "benchmark: runs a benchmark script")
 	search = flag.Bool("search", false, "search with -lat -lng")
 	lat    = flag.Float64("lat", -31.9523, "search Latitude")
 	lng    = flag.Float64("lng", -115.8613, "search Longitude")
 
+	serve = flag.Bool("serve", false, "serve: runs an HTTP lookup server on -addr")
+	addr  = flag.String("addr", ":8080", "address of the HTTP lookup server")
+	watch = flag.Duration("watch", 0, "interval to check the -db file for changes and reload it while serving, SIGHUP also reloads it")
+
+	export = flag.String("export", "", "export: writes the -db timezone data to a GeoJSON file, or to stdout with -export -")
+	zone   = flag.String("zone", "", "timezone to export, all timezones when empty. ex: Europe/Berlin")
+
 	build         = flag.Bool("build", false, "build: is used to download and build timezone data")
 	url           = flag.String("url", timezone.DefaultURL, "Url for data source as a zipfile")
+	input         = flag.String("input", "", "local GeoJSON file (plain, gzip or zip) or directory of GeoJSON files to build from instead of -url")
 	dbFilename    = flag.String("db", "timezone.data", "filename where timezone polygon data will be stored")
+	lenient       = flag.Bool("lenient", false, "skip GeoJSON features that can not be decoded and report them as warnings")
+	precision     = flag.String("precision", "float32", "precision of the stored vertices: float32, float64 or microdegrees")
+	codec         = flag.String("codec", "raw", "codec of the stored polygons: raw or delta (delta and zigzag varint encoded fixed-point vertices)")
+	simplify      = flag.Float64("simplify", 0, "tolerance in meters to simplify the polygons with, shared borders stay coincident. 0 does not simplify")
 	cacheFilename = flag.String("cache", "/tmp/geoJSON.zip", "cache directory for downloaded zipfile")
 )
 
@@ -46,12 +63,27 @@ func main() {
 		fmt.Println("Latitude:", res.Coordinates.Lat, "Longitude:", res.Coordinates.Lng, "Timezone:", res.Name, "Lookup time:", res.Elapsed)
 		fmt.Println("Search took:", time.Since(start))
 
+	} else if *serve {
+		if err := serveTimezone(); err != nil {
+			log.Fatalln(err)
+		}
+	} else if *export != "" {
+		if err := exportTimezone(); err != nil {
+			log.Fatalln(err)
+		}
 	} else {
 		fmt.Println("Please choose one of the following options:")
 		fmt.Println("\t", flag.Lookup("build").Usage)
 		fmt.Println("\t\t", "example: timezone -build")
+		fmt.Println("\t\t", "example: timezone -build -input timezones.geojson.gz")
+		fmt.Println("\t\t", "example: timezone -build -simplify 100")
+		fmt.Println("\t\t", "example: timezone -build -codec delta")
 		fmt.Println("\t", flag.Lookup("search").Usage)
 		fmt.Println("\t\t", "example: timezone -search -lat 10.34343 -lng -96.3444")
+		fmt.Println("\t", flag.Lookup("serve").Usage)
+		fmt.Println("\t\t", "example: timezone -serve -addr :8080")
+		fmt.Println("\t", flag.Lookup("export").Usage)
+		fmt.Println("\t\t", "example: timezone -export timezones.geojson -zone Europe/Berlin")
 	}
 
 }
@@ -71,21 +103,133 @@ func searchTimezone(lat, lng float64) (timezone.Result, error) {
 	return tzc.Search(lat, lng)
 }
 
+func serveTimezone() error {
+	tzc, err := timezone.NewReloader(*dbFilename, nil)
+	if err != nil {
+		return err
+	}
+	defer tzc.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	tzc.OnReload(func(err error) {
+		if err != nil {
+			log.Println("Reloading timezone database:", err)
+			return
+		}
+		fmt.Println("Reloaded timezone database:", *dbFilename, "Release:", tzc.Info().Release)
+	})
+	go tzc.WatchSignal(ctx, syscall.SIGHUP)
+	if *watch > 0 {
+		go tzc.WatchFile(ctx, *watch)
+	}
+
+	srv := &http.Server{Addr: *addr, Handler: server.New(tzc)}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+	fmt.Println("Serving timezone database:", *dbFilename, "on:", *addr)
+	if err = srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	<-done // wait for in-flight requests before the database is closed
+	return nil
+}
+
+func exportTimezone() (err error) {
+	var tzc timezone.Timezonecache
+	f, err := os.Open(*dbFilename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err = tzc.Load(f); err != nil {
+		return err
+	}
+	defer tzc.Close()
+
+	var zones []string
+	if *zone != "" {
+		zones = append(zones, *zone)
+	}
+	if *export == "-" {
+		return tzc.ExportGeoJSON(os.Stdout, zones...)
+	}
+	out, err := os.Create(*export)
+	if err != nil {
+		return err
+	}
+	if err = tzc.ExportGeoJSON(out, zones...); err != nil {
+		out.Close()
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	fmt.Println("Exported timezone data to:", *export)
+	return nil
+}
+
 func downloadAndBuild() (err error) {
 	var tzc timezone.Timezonecache
+	prec, err := geo.ParsePrecision(*precision)
+	if err != nil {
+		return fmt.Errorf("%w: %q", err, *precision)
+	}
+	if err = tzc.SetPrecision(prec); err != nil {
+		return err
+	}
+	c, err := geo.ParseCodec(*codec)
+	if err != nil {
+		return fmt.Errorf("%w: %q", err, *codec)
+	}
+	if err = tzc.SetCodec(c); err != nil {
+		return err
+	}
 	var total int
-	err = timezone.ImportZipFile(*cacheFilename, *url, func(tz timezone.Timezone) error {
+	add := func(tz timezone.Timezone) error {
 		total += len(tz.Polygons)
 		tzc.AddTimezone(tz)
 		return nil
-	})
+	}
+	im := timezone.Importer{Lenient: *lenient}
+	if *input != "" {
+		err = im.ImportFile(context.Background(), *input, add)
+	} else {
+		tzc.SetRelease(timezone.ReleaseFromURL(*url))
+		err = im.ImportZipFile(context.Background(), *cacheFilename, *url, add)
+	}
+	for _, w := range im.Warnings {
+		log.Println("Skipped:", w)
+	}
 	if err != nil {
 		return err
 	}
+	var ref *timezone.Timezonecache
+	var refSize int64
+	if *simplify != 0 {
+		if ref, refSize, err = simplifyTimezones(&tzc, *simplify); err != nil {
+			return err
+		}
+		defer ref.Close()
+	}
 	if err = tzc.Save(*dbFilename); err != nil {
 		return err
 	}
 	fmt.Println("Polygons added:", total)
-	fmt.Println("Saved Timezone data to:", *dbFilename)
+	fmt.Println("Saved Timezone data to:", *dbFilename, "Release:", tzc.Info().Release, "Precision:", prec, "Codec:", c)
+	if ref != nil {
+		fi, err := os.Stat(*dbFilename)
+		if err != nil {
+			return err
+		}
+		return reportSimplify(ref, &tzc, refSize, fi.Size(), *simplify)
+	}
 	return nil
 }
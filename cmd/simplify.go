@@ -0,0 +1,124 @@
+// Copyright 2018-2022 Evan Oberholster.
+//
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"time"
+
+	timezone "github.com/evanoberholster/timezoneLookup/v2"
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+)
+
+// samplePoints is the number of points of each sample of reportSimplify
+const samplePoints = 100000
+
+// simplifyTimezones simplifies the polygons of tzc with a tolerance in meters. The
+// unsimplified timezone data is returned to compare with, with its size in bytes.
+func simplifyTimezones(tzc *timezone.Timezonecache, tolerance float64) (*timezone.Timezonecache, int64, error) {
+	f, err := os.CreateTemp("", "timezone.*.data")
+	if err != nil {
+		return nil, 0, err
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+	if err = tzc.Save(f.Name()); err != nil {
+		return nil, 0, err
+	}
+	b, err := os.ReadFile(f.Name())
+	if err != nil {
+		return nil, 0, err
+	}
+	ref := new(timezone.Timezonecache)
+	if err = ref.LoadBytes(b); err != nil {
+		return nil, 0, err
+	}
+
+	start := time.Now()
+	if err = tzc.Simplify(tolerance); err != nil {
+		ref.Close()
+		return nil, 0, err
+	}
+	fmt.Println("Simplified polygons with a tolerance of", tolerance, "meters in:", time.Since(start))
+	return ref, int64(len(b)), nil
+}
+
+// reportSimplify prints the size and the accuracy of the simplified timezone data
+// compared with the unsimplified timezone data ref. The accuracy is the share of
+// lookups with the same timezone for random points on the globe and for random
+// points near the borders of the unsimplified polygons.
+func reportSimplify(ref, tzc *timezone.Timezonecache, refSize, size int64, tolerance float64) error {
+	refVertices, border, err := sampleBorders(ref, tolerance)
+	if err != nil {
+		return err
+	}
+	vertices, _, err := sampleBorders(tzc, 0)
+	if err != nil {
+		return err
+	}
+	r := rand.New(rand.NewSource(1))
+	globe := make([]geo.LatLng, samplePoints)
+	for i := range globe {
+		// uniform on the sphere
+		globe[i] = geo.NewLatLng(math.Asin(2*r.Float64()-1)*180/math.Pi, r.Float64()*360-180)
+	}
+
+	fmt.Printf("Size: %.1f MB -> %.1f MB (%.1f%%)\n", float64(refSize)/1e6, float64(size)/1e6, 100*float64(size)/float64(refSize))
+	fmt.Printf("Vertices: %d -> %d (%.1f%%)\n", refVertices, vertices, 100*float64(vertices)/float64(refVertices))
+	for _, sample := range []struct {
+		name   string
+		points []geo.LatLng
+	}{{"Random points", globe}, {"Points near borders", border}} {
+		var differ int
+		for _, ll := range sample.points {
+			a, _ := ref.Search(ll.Lat, ll.Lng)
+			b, _ := tzc.Search(ll.Lat, ll.Lng)
+			if a.Name != b.Name {
+				differ++
+			}
+		}
+		fmt.Printf("%s: %d of %d have the same timezone (%.3f%%)\n", sample.name, len(sample.points)-differ, len(sample.points),
+			100*float64(len(sample.points)-differ)/float64(len(sample.points)))
+	}
+	return nil
+}
+
+// sampleBorders returns the number of vertices of the polygons of tzc and
+// samplePoints random points within distance meters of its vertices.
+func sampleBorders(tzc *timezone.Timezonecache, distance float64) (vertices int, points []geo.LatLng, err error) {
+	var all []geo.LatLng
+	for _, name := range tzc.Zones() {
+		z, err := tzc.Zone(name)
+		if err != nil {
+			return 0, nil, err
+		}
+		vertices += z.Vertices
+		if distance == 0 {
+			continue
+		}
+		for i := range z.Polygons {
+			for h := 0; h <= z.Polygons[i].Holes(); h++ {
+				all = append(all, z.Polygons[i].Ring(h)...)
+			}
+		}
+	}
+	if len(all) == 0 {
+		return vertices, nil, nil
+	}
+	r := rand.New(rand.NewSource(2))
+	points = make([]geo.LatLng, samplePoints)
+	for i := range points {
+		ll := all[r.Intn(len(all))]
+		dLat := distance / geo.MetersPerDegree
+		dLng := dLat / math.Max(math.Cos(ll.Lat*math.Pi/180), 0.01)
+		ll.Lat = math.Max(-90, math.Min(90, ll.Lat+(2*r.Float64()-1)*dLat))
+		ll.Lng = math.Max(-180, math.Min(180, ll.Lng+(2*r.Float64()-1)*dLng))
+		points[i] = ll
+	}
+	return vertices, points, nil
+}
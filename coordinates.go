@@ -0,0 +1,262 @@
+package timezoneLookup
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+)
+
+// feature is a GeoJSON Feature with its coordinates decoded directly into
+// vertices, without the []interface{} trees of GeoJSONFeature. A feature
+// is reused between features to reuse the vertex buffers.
+type feature struct {
+	Type       string `json:"type"`
+	Properties struct {
+		Tzid string `json:"tzid"`
+	} `json:"properties"`
+	Geometry struct {
+		Item        string      `json:"type"`
+		Coordinates coordinates `json:"coordinates"`
+	} `json:"geometry"`
+}
+
+func (f *feature) reset() {
+	f.Type, f.Properties.Tzid, f.Geometry.Item = "", "", ""
+	f.Geometry.Coordinates.reset()
+}
+
+// timezone returns the timezone of the feature
+func (f *feature) timezone() (tz Timezone, err error) {
+	if f.Properties.Tzid == "" {
+		return tz, fmt.Errorf("%w: missing tzid", ErrInvalidFeature)
+	}
+	tz.Name = f.Properties.Tzid
+	switch f.Geometry.Item {
+	case "Polygon":
+		tz.Polygons, err = f.Geometry.Coordinates.polygons(polygonDepth)
+	case "MultiPolygon":
+		tz.Polygons, err = f.Geometry.Coordinates.polygons(multiPolygonDepth)
+	default:
+		err = fmt.Errorf("%w: unsupported geometry type %q", ErrInvalidFeature, f.Geometry.Item)
+	}
+	tz.Polygons = splitAntimeridian(tz.Polygons)
+	return tz, err
+}
+
+// Nesting depth of the positions of GeoJSON geometries
+const (
+	polygonDepth      = 3 // [[[lng, lat]]]
+	multiPolygonDepth = 4 // [[[[lng, lat]]]]
+)
+
+// coordinates are the coordinates of a GeoJSON geometry decoded into a flat
+// list of vertices and the ends of the arrays that contain them.
+type coordinates struct {
+	v     []geo.LatLng // vertices of all rings
+	ends  []arrayEnd   // arrays in the order they are closed
+	depth int          // nesting depth of the positions, 0 when there are none
+	err   error        // error of the coordinates, reported by polygons
+}
+
+// arrayEnd is the end of a JSON array of coordinates
+type arrayEnd struct {
+	depth    int // nesting depth of the array, the coordinates are depth 1
+	vertices int // number of vertices decoded when the array was closed
+}
+
+func (c *coordinates) reset() {
+	c.v, c.ends, c.depth, c.err = c.v[:0], c.ends[:0], 0, nil
+}
+
+// UnmarshalJSON decodes the coordinates. Invalid coordinates do not return an
+// error so that decoding of the JSON stream continues, the error is returned
+// by polygons instead.
+func (c *coordinates) UnmarshalJSON(b []byte) error {
+	c.reset()
+	d := coordinateDecoder{b: b, c: c}
+	d.skipSpace()
+	err := d.array(1)
+	if d.skipSpace(); err == nil && d.i < len(b) {
+		err = d.errorf("unexpected %q", b[d.i])
+	}
+	c.err = err
+	return nil
+}
+
+// polygons returns the polygons of coordinates with positions nested depth deep
+func (c *coordinates) polygons(depth int) ([]geo.Polygon, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if c.depth != depth {
+		return nil, fmt.Errorf("%w: coordinates are not nested %d arrays deep", ErrInvalidFeature, depth)
+	}
+	var pp []geo.Polygon
+	var rings []int // vertex ends of the rings of the current polygon
+	start, ringStart := 0, 0
+	for _, e := range c.ends {
+		switch e.depth {
+		case depth - 1: // ring
+			// a linear ring is closed and has at least 4 positions
+			if n := e.vertices - ringStart; n < 4 {
+				return nil, fmt.Errorf("%w: polygon %d ring %d has %d positions, at least 4 are required", ErrInvalidFeature, len(pp), len(rings), n)
+			}
+			rings = append(rings, e.vertices)
+			ringStart = e.vertices
+		case depth - 2: // polygon
+			if len(rings) == 0 {
+				return nil, fmt.Errorf("%w: polygon %d has no rings", ErrInvalidFeature, len(pp))
+			}
+			v := append([]geo.LatLng(nil), c.v[start:e.vertices]...)
+			p := geo.NewPolygonFromVertices(v[:rings[0]-start])
+			for i := 1; i < len(rings); i++ {
+				p.AddHole()
+				for _, ll := range c.v[rings[i-1]:rings[i]] {
+					p.AddVertex(ll)
+				}
+			}
+			pp = append(pp, p)
+			rings, start = rings[:0], e.vertices
+		}
+	}
+	if len(pp) == 0 {
+		return nil, fmt.Errorf("%w: geometry has no polygons", ErrInvalidFeature)
+	}
+	return pp, nil
+}
+
+// coordinateDecoder decodes the nested arrays of GeoJSON coordinates. b has
+// been checked to be valid JSON by encoding/json.
+type coordinateDecoder struct {
+	b []byte
+	i int
+	c *coordinates
+}
+
+func (d *coordinateDecoder) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: coordinates at offset %d: %s", ErrInvalidFeature, d.i, fmt.Sprintf(format, args...))
+}
+
+func (d *coordinateDecoder) skipSpace() {
+	for d.i < len(d.b) {
+		switch d.b[d.i] {
+		case ' ', '\t', '\r', '\n':
+			d.i++
+		default:
+			return
+		}
+	}
+}
+
+// array decodes an array of arrays or a position at the nesting depth
+func (d *coordinateDecoder) array(depth int) error {
+	if depth > multiPolygonDepth {
+		return d.errorf("arrays are nested more than %d deep", multiPolygonDepth)
+	}
+	if d.i >= len(d.b) || d.b[d.i] != '[' {
+		return d.errorf("not an array")
+	}
+	d.i++
+	d.skipSpace()
+	if d.i < len(d.b) && isNumberStart(d.b[d.i]) {
+		return d.position(depth)
+	}
+	if d.i < len(d.b) && d.b[d.i] == ']' {
+		d.i++
+		d.c.ends = append(d.c.ends, arrayEnd{depth: depth, vertices: len(d.c.v)})
+		return nil
+	}
+	if d.i < len(d.b) && d.b[d.i] != '[' {
+		return d.errorf("expected a number or an array, found %q", d.b[d.i])
+	}
+	for {
+		if err := d.array(depth + 1); err != nil {
+			return err
+		}
+		d.skipSpace()
+		if d.i >= len(d.b) {
+			return d.errorf("unexpected end")
+		}
+		switch d.b[d.i] {
+		case ',':
+			d.i++
+			d.skipSpace()
+		case ']':
+			d.i++
+			d.c.ends = append(d.c.ends, arrayEnd{depth: depth, vertices: len(d.c.v)})
+			return nil
+		default:
+			return d.errorf("unexpected %q", d.b[d.i])
+		}
+	}
+}
+
+// position decodes the numbers of a position after its '['. Any numbers
+// after the longitude and latitude, ex: altitude, are ignored.
+func (d *coordinateDecoder) position(depth int) error {
+	if d.c.depth == 0 {
+		d.c.depth = depth
+	} else if d.c.depth != depth {
+		return d.errorf("positions are nested at different depths")
+	}
+	start := d.i
+	var ll [2]float64
+	for n := 0; ; n++ {
+		f, err := d.number()
+		if err != nil {
+			return err
+		}
+		if n < 2 {
+			ll[n] = f
+		}
+		d.skipSpace()
+		if d.i >= len(d.b) {
+			return d.errorf("unexpected end")
+		}
+		if d.b[d.i] == ']' {
+			d.i++
+			if n < 1 {
+				return d.errorf("position is not an array of 2 numbers")
+			}
+			break
+		}
+		if d.b[d.i] != ',' {
+			return d.errorf("position is not an array of numbers")
+		}
+		d.i++
+		d.skipSpace()
+	}
+	latlng := geo.NewLatLng(ll[1], ll[0])
+	if math.IsInf(ll[0], 0) || math.IsInf(ll[1], 0) || !latlng.Valid() {
+		d.i = start
+		return d.errorf("position [%v %v] is not a valid latitude and longitude", ll[0], ll[1])
+	}
+	d.c.v = append(d.c.v, latlng)
+	return nil
+}
+
+func (d *coordinateDecoder) number() (float64, error) {
+	start := d.i
+	for d.i < len(d.b) && (isNumberStart(d.b[d.i]) || d.b[d.i] == '.' || d.b[d.i] == 'e' || d.b[d.i] == 'E' || d.b[d.i] == '+') {
+		d.i++
+	}
+	f, err := strconv.ParseFloat(string(d.b[start:d.i]), 64)
+	if err != nil && !isRangeError(err) {
+		d.i = start
+		return 0, d.errorf("not a number")
+	}
+	return f, nil
+}
+
+func isNumberStart(c byte) bool {
+	return c == '-' || (c >= '0' && c <= '9')
+}
+
+// isRangeError returns true for numbers that are out of range, which are
+// rejected as invalid coordinates instead.
+func isRangeError(err error) bool {
+	ne, ok := err.(*strconv.NumError)
+	return ok && ne.Err == strconv.ErrRange
+}
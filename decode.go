@@ -0,0 +1,319 @@
+package timezoneLookup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+)
+
+var (
+	ErrInvalidFeature = errors.New("error invalid GeoJSON feature")
+)
+
+// DecodeError is an error decoding GeoJSON timezone boundaries
+type DecodeError struct {
+	File    string // Name of the file, empty for ImportGeoJSON. ex: "timezones.zip/combined.json"
+	Feature int    // Index of the feature, -1 when the error is not within a feature
+	Tzid    string // tzid of the feature when it is known
+	Offset  int64  // Offset in bytes of the feature, or of the error for malformed JSON
+	Err     error
+}
+
+func (e *DecodeError) Error() string {
+	s := "error decoding GeoJSON"
+	if e.File != "" {
+		s += " " + e.File
+	}
+	if e.Feature >= 0 {
+		s += fmt.Sprintf(" feature %d", e.Feature)
+	}
+	if e.Tzid != "" {
+		s += fmt.Sprintf(" (tzid %q)", e.Tzid)
+	}
+	return s + fmt.Sprintf(" at offset %d: %v", e.Offset, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// jsonError returns a *DecodeError for malformed JSON
+func jsonError(name string, dec *json.Decoder, feature int, err error) error {
+	offset := dec.InputOffset()
+	var se *json.SyntaxError
+	if errors.As(err, &se) {
+		offset = se.Offset
+	}
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return &DecodeError{File: name, Feature: feature, Offset: offset, Err: err}
+}
+
+// valueOffset returns the offset of the next value of dec in an array, past the
+// comma and whitespace in front of it. InputOffset is at the comma, while the
+// buffered data may still start with the whitespace in front of the comma.
+func valueOffset(dec *json.Decoder) int64 {
+	offset := dec.InputOffset()
+	br, ok := dec.Buffered().(io.ByteReader)
+	if !ok {
+		return offset
+	}
+	var comma bool
+	for {
+		c, err := br.ReadByte()
+		if err != nil {
+			return offset
+		}
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			if comma {
+				offset++
+			}
+		case ',':
+			if comma {
+				return offset
+			}
+			comma = true
+			offset++
+		default:
+			return offset
+		}
+	}
+}
+
+// isTypeError returns true when err is a JSON value of the wrong type, after which decoding can continue
+func isTypeError(err error) bool {
+	var te *json.UnmarshalTypeError
+	return errors.As(err, &te)
+}
+
+// featureError returns a *DecodeError for feature i that can not be decoded. In lenient
+// mode the error is added to the warnings and nil is returned.
+func (im *Importer) featureError(name string, i int, tzid string, offset int64, err error) error {
+	de := &DecodeError{File: name, Feature: i, Tzid: tzid, Offset: offset, Err: err}
+	if !im.Lenient {
+		return de
+	}
+	im.Warnings = append(im.Warnings, de)
+	return nil
+}
+
+// decodeJSON decodes a GeoJSON FeatureCollection, whose features are decoded
+// one at a time, or a single GeoJSON Feature from r. name is the file name of r.
+func (im *Importer) decodeJSON(ctx context.Context, name string, r io.Reader, iter func(tz Timezone) error) error {
+	dec := json.NewDecoder(r)
+	token, err := dec.Token()
+	if err != nil {
+		return jsonError(name, dec, -1, err)
+	}
+	if token != json.Delim('{') {
+		return &DecodeError{File: name, Feature: -1, Err: errors.New("GeoJSON is not an object")}
+	}
+
+	var f feature
+	var featureErr error // error of a single feature, decoding continues to validate the JSON
+	var features bool
+	for dec.More() {
+		if token, err = dec.Token(); err != nil {
+			return jsonError(name, dec, -1, err)
+		}
+		switch token {
+		case "type":
+			err = dec.Decode(&f.Type)
+		case "properties":
+			err = dec.Decode(&f.Properties)
+		case "geometry":
+			err = dec.Decode(&f.Geometry)
+		case "features":
+			if token, err = dec.Token(); err != nil {
+				return jsonError(name, dec, -1, err)
+			}
+			if token != json.Delim('[') {
+				return &DecodeError{File: name, Feature: -1, Offset: dec.InputOffset(), Err: errors.New("GeoJSON features is not an array")}
+			}
+			if err = im.decodeFeatures(ctx, name, dec, iter); err != nil {
+				return err
+			}
+			if _, err = dec.Token(); err != nil { // ]
+				return jsonError(name, dec, -1, err)
+			}
+			features = true
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if isTypeError(err) {
+			featureErr, err = err, nil
+		}
+		if err != nil {
+			return jsonError(name, dec, -1, err)
+		}
+	}
+	if _, err = dec.Token(); err != nil { // }
+		return jsonError(name, dec, -1, err)
+	}
+	switch {
+	case features:
+		return nil
+	case f.Type == "Feature":
+		var tz Timezone
+		if featureErr == nil {
+			if tz, featureErr = f.timezone(); featureErr == nil {
+				return iter(tz)
+			}
+		}
+		return im.featureError(name, 0, f.Properties.Tzid, 0, featureErr)
+	}
+	return &DecodeError{File: name, Feature: -1, Err: errors.New("no features found")}
+}
+
+// decodeFeatures decodes the features of a FeatureCollection one at a time
+func (im *Importer) decodeFeatures(ctx context.Context, name string, dec *json.Decoder, fn func(tz Timezone) error) error {
+	var f feature
+	for i := 0; dec.More(); i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		offset := valueOffset(dec)
+		f.reset()
+		err := dec.Decode(&f)
+		if err != nil && !isTypeError(err) {
+			return jsonError(name, dec, i, err)
+		}
+		if err == nil {
+			var tz Timezone
+			if tz, err = f.timezone(); err == nil {
+				if err = fn(tz); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if err = im.featureError(name, i, f.Properties.Tzid, offset, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Timezone decodes the timezone of a GeoJSON Feature that was decoded with encoding/json.
+// The importers decode coordinates directly into vertices instead.
+func (f GeoJSONFeature) Timezone() (tz Timezone, err error) {
+	if f.Properties.Tzid == "" {
+		return tz, fmt.Errorf("%w: missing tzid", ErrInvalidFeature)
+	}
+	tz.Name = f.Properties.Tzid
+	switch f.Geometry.Item {
+	case "Polygon":
+		tz.Polygons, err = decodePolygons(f.Geometry.Coordinates)
+	case "MultiPolygon":
+		tz.Polygons, err = decodeMultiPolygons(f.Geometry.Coordinates)
+	default:
+		err = fmt.Errorf("%w: unsupported geometry type %q", ErrInvalidFeature, f.Geometry.Item)
+	}
+	tz.Polygons = splitAntimeridian(tz.Polygons)
+	return tz, err
+}
+
+// splitAntimeridian splits the polygons that cross the antimeridian at ±180°,
+// whose bounding boxes would otherwise span every longitude.
+func splitAntimeridian(pp []geo.Polygon) []geo.Polygon {
+	for i := range pp {
+		if !pp[i].CrossesAntimeridian() {
+			continue
+		}
+		split := append([]geo.Polygon(nil), pp[:i]...)
+		for _, p := range pp[i:] {
+			split = append(split, p.SplitAntimeridian()...)
+		}
+		return split
+	}
+	return pp
+}
+
+// decodePolygons decodes the rings of a GeoJSON Polygon. The first ring is the
+// exterior ring and any following rings are holes.
+// GeoJSON Spec https://geojson.org/geojson-spec.html
+// Coordinates: [Longitude, Latitude]
+func decodePolygons(polygons []interface{}) ([]geo.Polygon, error) {
+	p, err := decodePolygon(polygons)
+	if err != nil {
+		return nil, err
+	}
+	return []geo.Polygon{p}, nil
+}
+
+// decodeMultiPolygons decodes each GeoJSON Polygon of a MultiPolygon.
+// GeoJSON Spec https://geojson.org/geojson-spec.html
+// Coordinates: [Longitude, Latitude]
+func decodeMultiPolygons(polygons []interface{}) ([]geo.Polygon, error) {
+	if len(polygons) == 0 {
+		return nil, fmt.Errorf("%w: MultiPolygon has no polygons", ErrInvalidFeature)
+	}
+	pp := make([]geo.Polygon, 0, len(polygons))
+	for i, v := range polygons {
+		rings, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: polygon %d is not an array", ErrInvalidFeature, i)
+		}
+		p, err := decodePolygon(rings)
+		if err != nil {
+			return nil, fmt.Errorf("polygon %d: %w", i, err)
+		}
+		pp = append(pp, p)
+	}
+	return pp, nil
+}
+
+// decodePolygon decodes the rings of a single GeoJSON Polygon.
+func decodePolygon(rings []interface{}) (geo.Polygon, error) {
+	p := geo.NewPolygon()
+	if len(rings) == 0 {
+		return p, fmt.Errorf("%w: polygon has no rings", ErrInvalidFeature)
+	}
+	for r, v := range rings {
+		points, ok := v.([]interface{})
+		if !ok {
+			return p, fmt.Errorf("%w: ring %d is not an array", ErrInvalidFeature, r)
+		}
+		// a linear ring is closed and has at least 4 positions
+		if len(points) < 4 {
+			return p, fmt.Errorf("%w: ring %d has %d positions, at least 4 are required", ErrInvalidFeature, r, len(points))
+		}
+		if r > 0 {
+			p.AddHole()
+		}
+		for i, point := range points {
+			ll, err := decodePosition(point)
+			if err != nil {
+				return p, fmt.Errorf("ring %d position %d: %w", r, i, err)
+			}
+			p.AddVertex(ll)
+		}
+	}
+	return p, nil
+}
+
+// decodePosition decodes a GeoJSON position: [Longitude, Latitude]
+func decodePosition(v interface{}) (geo.LatLng, error) {
+	position, ok := v.([]interface{})
+	if !ok || len(position) < 2 {
+		return geo.LatLng{}, fmt.Errorf("%w: position is not an array of 2 numbers", ErrInvalidFeature)
+	}
+	lng, ok1 := position[0].(float64)
+	lat, ok2 := position[1].(float64)
+	if !ok1 || !ok2 {
+		return geo.LatLng{}, fmt.Errorf("%w: position is not an array of 2 numbers", ErrInvalidFeature)
+	}
+	ll := geo.NewLatLng(lat, lng)
+	if math.IsInf(lat, 0) || math.IsInf(lng, 0) || !ll.Valid() {
+		return ll, fmt.Errorf("%w: position %v is not a valid latitude and longitude", ErrInvalidFeature, position[:2])
+	}
+	return ll, nil
+}
@@ -0,0 +1,69 @@
+package timezoneLookup
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"testing"
+)
+
+//go:embed testdata/timezones.geojson
+var timezonesGeoJSON []byte
+
+// BenchmarkDecodeInterface decodes the features into GeoJSONFeature, with
+// coordinates as []interface{}, and converts them to polygons.
+func BenchmarkDecodeInterface(b *testing.B) {
+	benchmarkDecode(b, func() (polygons int, err error) {
+		dec := json.NewDecoder(bytes.NewReader(timezonesGeoJSON))
+		for {
+			token, err := dec.Token()
+			if err != nil {
+				return 0, err
+			}
+			if token == "features" {
+				break
+			}
+		}
+		if _, err = dec.Token(); err != nil { // [
+			return 0, err
+		}
+		for dec.More() {
+			var f GeoJSONFeature
+			if err = dec.Decode(&f); err != nil {
+				return 0, err
+			}
+			tz, err := f.Timezone()
+			if err != nil {
+				return 0, err
+			}
+			polygons += len(tz.Polygons)
+		}
+		return polygons, nil
+	})
+}
+
+// BenchmarkDecodeStreaming decodes the features with the importer, which
+// decodes coordinates directly into vertices.
+func BenchmarkDecodeStreaming(b *testing.B) {
+	benchmarkDecode(b, func() (polygons int, err error) {
+		err = ImportGeoJSON(bytes.NewReader(timezonesGeoJSON), func(tz Timezone) error {
+			polygons += len(tz.Polygons)
+			return nil
+		})
+		return polygons, err
+	})
+}
+
+func benchmarkDecode(b *testing.B, decode func() (int, error)) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(timezonesGeoJSON)))
+	for i := 0; i < b.N; i++ {
+		polygons, err := decode()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if polygons != 15 {
+			b.Fatalf("decoded %d polygons, want 15", polygons)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+//go:build go1.18
+// +build go1.18
+
+package timezoneLookup
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+)
+
+func FuzzImportGeoJSON(f *testing.F) {
+	b, err := os.ReadFile("testdata/timezones.geojson")
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(b)
+	f.Add([]byte(featureA))
+	f.Add([]byte(collection + featureA + "," + featurePnt + "," + featureNaN + "," + featureLat + "," + featureTzid + "," + featureRing + "]}"))
+	f.Add([]byte(collection + featureA + `,{"type":"Feature",`))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		strict, strictErr := importFuzz(t, &Importer{}, b)
+		im := Importer{Lenient: true}
+		lenient, lenientErr := importFuzz(t, &im, b)
+		for _, w := range im.Warnings {
+			if w.Feature < 0 || w.Err == nil {
+				t.Fatalf("warning %v", w)
+			}
+		}
+		if strictErr == nil && (lenientErr != nil || len(im.Warnings) > 0 || lenient != strict) {
+			t.Fatalf("lenient import of %d timezones with %d warnings: %v, strict import of %d timezones",
+				lenient, len(im.Warnings), lenientErr, strict)
+		}
+	})
+}
+
+// importFuzz imports b with im and returns the number of timezones. Fails when
+// the error is not a *DecodeError or a polygon is not valid.
+func importFuzz(t *testing.T, im *Importer, b []byte) (int, error) {
+	var timezones int
+	err := im.ImportGeoJSON(context.Background(), bytes.NewReader(b), func(tz Timezone) error {
+		timezones++
+		if tz.Name == "" || len(tz.Polygons) == 0 {
+			t.Fatalf("timezone %q with %d polygons", tz.Name, len(tz.Polygons))
+		}
+		for _, p := range tz.Polygons {
+			for i := 0; i <= p.Holes(); i++ {
+				for _, ll := range p.Ring(i) {
+					if !ll.Valid() {
+						t.Fatalf("timezone %q vertex %v is not valid", tz.Name, ll)
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if _, ok := err.(*DecodeError); err != nil && !ok {
+		t.Fatalf("error %T is not a *DecodeError: %v", err, err)
+	}
+	return timezones, err
+}
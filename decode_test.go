@@ -0,0 +1,103 @@
+package timezoneLookup
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+const (
+	collection  = `{"type":"FeatureCollection","features":[`
+	featureA    = `{"type":"Feature","properties":{"tzid":"A"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}}`
+	featurePnt  = `{"type":"Feature","properties":{"tzid":"B"},"geometry":{"type":"Point","coordinates":[0,0]}}`
+	featureNaN  = `{"type":"Feature","properties":{"tzid":"C"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,"x"],[0,0]]]}}`
+	featureLat  = `{"type":"Feature","properties":{"tzid":"D"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,95],[0,0]]]}}`
+	featureTzid = `{"type":"Feature","properties":{},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}}`
+	featureRing = `{"type":"Feature","properties":{"tzid":"E"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0]]]}}`
+)
+
+func TestDecodeError(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		feature int
+		tzid    string
+		at      string // the error is at the last offset of at in input, or 0 when empty
+		err     error
+		syntax  bool // the offset of malformed JSON is reported by encoding/json, at or after at
+	}{
+		{"geometry type", collection + featureA + "," + featurePnt + "]}", 1, "B", featurePnt, ErrInvalidFeature, false},
+		{"not a number", collection + featureA + ",\n  " + featureNaN + "]}", 1, "C", featureNaN, ErrInvalidFeature, false},
+		{"latitude", collection + featureA + "," + featureA + " , " + featureLat + "]}", 2, "D", featureLat, ErrInvalidFeature, false},
+		{"missing tzid", collection + featureTzid + "]}", 0, "", featureTzid, ErrInvalidFeature, false},
+		{"ring", featureRing, 0, "E", "", ErrInvalidFeature, false},
+		{"truncated", collection + featureA + `,{"type":"Feature",`, 1, "", `,{"type":"Feature",`, io.ErrUnexpectedEOF, true},
+		{"syntax", collection + featureA + `,{"type":"Feature",]}`, 1, "", `,{"type":"Feature",`, nil, true},
+		{"not an object", `[]`, -1, "", "", nil, false},
+		{"features", `{"type":"FeatureCollection","features":{}}`, -1, "", "}}", nil, true},
+		{"no features", `{"type":"FeatureCollection"}`, -1, "", "", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var timezones int
+			err := ImportGeoJSON(strings.NewReader(tt.input), func(tz Timezone) error {
+				timezones++
+				return nil
+			})
+			var de *DecodeError
+			if !errors.As(err, &de) {
+				t.Fatalf("error %v is not a *DecodeError", err)
+			}
+			offset := 0
+			if tt.at != "" {
+				offset = strings.LastIndex(tt.input, tt.at)
+			}
+			if tt.syntax && de.Offset >= int64(offset) && de.Offset <= int64(len(tt.input)) {
+				offset = int(de.Offset)
+			}
+			if de.Feature != tt.feature || de.Tzid != tt.tzid || de.Offset != int64(offset) {
+				t.Fatalf("feature %d tzid %q offset %d, want feature %d tzid %q offset %d: %v",
+					de.Feature, de.Tzid, de.Offset, tt.feature, tt.tzid, offset, err)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Fatalf("error %v is not %v", err, tt.err)
+			}
+			if tt.feature > 0 && timezones != tt.feature {
+				t.Fatalf("%d timezones imported before feature %d", timezones, tt.feature)
+			}
+		})
+	}
+}
+
+func TestImporterLenient(t *testing.T) {
+	input := collection + featureA + "," + featurePnt + "," + featureNaN + "," + featureLat + "," + featureA + "]}"
+	im := Importer{Lenient: true}
+	var names []string
+	if err := im.ImportGeoJSON(context.Background(), strings.NewReader(input), func(tz Timezone) error {
+		names = append(names, tz.Name)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0] != "A" || names[1] != "A" {
+		t.Fatalf("imported %v", names)
+	}
+	if len(im.Warnings) != 3 {
+		t.Fatalf("%d warnings", len(im.Warnings))
+	}
+	for i, w := range im.Warnings {
+		if w.Feature != i+1 || w.Tzid != string(rune('B'+i)) || !errors.Is(w, ErrInvalidFeature) {
+			t.Fatalf("warning %d: %v", i, w)
+		}
+	}
+
+	// malformed JSON still stops the import
+	im = Importer{Lenient: true}
+	err := im.ImportGeoJSON(context.Background(), strings.NewReader(collection+featureA+`,{"type":`), func(tz Timezone) error { return nil })
+	var de *DecodeError
+	if !errors.As(err, &de) || de.Feature != 1 {
+		t.Fatalf("malformed JSON: %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package timezoneLookup
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+)
+
+// ExportGeoJSON writes the timezones to w as a GeoJSON FeatureCollection with a
+// feature for every timezone in zone ID order. When names are given only those
+// timezones are written, and ErrUnknownZone is returned when one of them is not
+// in the zone table.
+//
+// Every feature has a "tzid" property and a Polygon or MultiPolygon geometry.
+// Coordinates are written so that importing the GeoJSON produces identical polygons.
+func (tzc *Timezonecache) ExportGeoJSON(w io.Writer, names ...string) error {
+	if err := tzc.rlock(); err != nil {
+		return err
+	}
+	defer tzc.mu.RUnlock()
+
+	// polygon ids by zone ID
+	polygons := make([][]uint, len(tzc.zones))
+	for id, zone := range tzc.zone {
+		polygons[zone] = append(polygons[zone], uint(id))
+	}
+	zones := make([]uint32, 0, len(tzc.zones))
+	if len(names) == 0 {
+		for zone := range tzc.zones {
+			zones = append(zones, uint32(zone))
+		}
+	}
+	for _, name := range names {
+		zone, ok := tzc.zoneID(name)
+		if !ok {
+			return ErrUnknownZone
+		}
+		zones = append(zones, zone)
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(`{"type":"FeatureCollection","features":[`)
+	prec := tzc.precision
+	if tzc.codec == geo.Delta && prec == geo.Float32 {
+		// delta vertices are fixed-point, not float32
+		prec = geo.Float64
+	}
+	var p geo.Polygon
+	var b []byte
+	for i, zone := range zones {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		b = append(b[:0], `{"type":"Feature","properties":{"tzid":`...)
+		name, _ := json.Marshal(tzc.zones[zone])
+		b = append(b, name...)
+		b = append(b, `},"geometry":{"type":`...)
+		if len(polygons[zone]) == 1 {
+			b = append(b, `"Polygon","coordinates":`...)
+		} else {
+			b = append(b, `"MultiPolygon","coordinates":[`...)
+		}
+		for j, id := range polygons[zone] {
+			if j > 0 {
+				b = append(b, ',')
+			}
+			tzc.codec.Decode(&p, tzc.buf(id), tzc.precision)
+			b = appendPolygon(b, &p, prec)
+		}
+		if len(polygons[zone]) != 1 {
+			b = append(b, ']')
+		}
+		b = append(b, "}}"...)
+		if _, err := bw.Write(b); err != nil {
+			return err
+		}
+	}
+	bw.WriteString("]}\n")
+	return bw.Flush()
+}
+
+// appendPolygon appends the rings of p as GeoJSON Polygon coordinates
+func appendPolygon(b []byte, p *geo.Polygon, prec geo.Precision) []byte {
+	b = append(b, '[')
+	for r := 0; r <= p.Holes(); r++ {
+		if r > 0 {
+			b = append(b, ',')
+		}
+		b = append(b, '[')
+		for i, ll := range p.Ring(r) {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = append(b, '[')
+			b = appendCoordinate(b, ll.Lng, prec)
+			b = append(b, ',')
+			b = appendCoordinate(b, ll.Lat, prec)
+			b = append(b, ']')
+		}
+		b = append(b, ']')
+	}
+	return append(b, ']')
+}
+
+// appendCoordinate appends the shortest decimal of f, a coordinate of precision
+// prec, that is decoded back to f when parsed as a float64 and rounded to prec,
+// as done by the importer.
+func appendCoordinate(b []byte, f float64, prec geo.Precision) []byte {
+	switch prec {
+	case geo.Float32:
+		n := len(b)
+		b = strconv.AppendFloat(b, f, 'f', -1, 32)
+		if v, err := strconv.ParseFloat(string(b[n:]), 64); err == nil && float32(v) == float32(f) {
+			return b
+		}
+		b = b[:n]
+	case geo.MicroDegrees:
+		n := len(b)
+		b = strconv.AppendFloat(b, f, 'f', 6, 64)
+		// trim trailing zeros
+		for b[len(b)-1] == '0' {
+			b = b[:len(b)-1]
+		}
+		if b[len(b)-1] == '.' {
+			b = b[:len(b)-1]
+		}
+		if string(b[n:]) == "-0" {
+			b = append(b[:n], '0')
+		}
+		return b
+	}
+	return strconv.AppendFloat(b, f, 'f', -1, 64)
+}
@@ -0,0 +1,99 @@
+package timezoneLookup
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+)
+
+// importTestdata returns a Timezonecache of testdata/timezones.geojson, or of r
+// when it is not nil, with the precision and codec.
+func importTestdata(t testing.TB, r io.Reader, prec geo.Precision, codec geo.Codec) *Timezonecache {
+	t.Helper()
+	if r == nil {
+		f, err := os.Open("testdata/timezones.geojson")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		r = f
+	}
+	tzc := new(Timezonecache)
+	if err := tzc.SetPrecision(prec); err != nil {
+		t.Fatal(err)
+	}
+	if err := tzc.SetCodec(codec); err != nil {
+		t.Fatal(err)
+	}
+	if err := ImportGeoJSON(r, func(tz Timezone) error {
+		tzc.AddTimezone(tz)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return tzc
+}
+
+// rings returns the rings of the polygons of every zone by name
+func rings(t testing.TB, tzc *Timezonecache) map[string][][]geo.LatLng {
+	t.Helper()
+	m := make(map[string][][]geo.LatLng)
+	for _, name := range tzc.Zones() {
+		zg, err := tzc.Zone(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range zg.Polygons {
+			for i := 0; i <= p.Holes(); i++ {
+				m[name] = append(m[name], p.Ring(i))
+			}
+		}
+	}
+	return m
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	for prec := geo.Float32; prec.Valid(); prec++ {
+		for codec := geo.Raw; codec.Valid(); codec++ {
+			t.Run(prec.String()+"/"+codec.String(), func(t *testing.T) {
+				imported := importTestdata(t, nil, prec, codec)
+				loaded := loadFile(t, saveFile(t, imported))
+				var buf bytes.Buffer
+				if err := loaded.ExportGeoJSON(&buf); err != nil {
+					t.Fatal(err)
+				}
+				exported := importTestdata(t, &buf, prec, codec)
+
+				want := rings(t, imported)
+				if len(want) != 13 || len(want["Pacific/Fiji"]) != 2 {
+					t.Fatalf("imported %d zones, %d Pacific/Fiji rings", len(want), len(want["Pacific/Fiji"]))
+				}
+				if got := rings(t, exported); !reflect.DeepEqual(got, want) {
+					t.Fatal("exported polygons differ from the imported polygons")
+				}
+				if got := rings(t, loaded); !reflect.DeepEqual(got, want) {
+					t.Fatal("loaded polygons differ from the imported polygons")
+				}
+			})
+		}
+	}
+}
+
+func TestExportZone(t *testing.T) {
+	tzc := importTestdata(t, nil, geo.Float32, geo.Raw)
+	var buf bytes.Buffer
+	if err := tzc.ExportGeoJSON(&buf, "Test/Multi"); err != nil {
+		t.Fatal(err)
+	}
+	exported := importTestdata(t, &buf, geo.Float32, geo.Raw)
+	if zones := exported.Zones(); len(zones) != 1 || zones[0] != "Test/Multi" {
+		t.Fatalf("exported zones %v", zones)
+	}
+	if err := tzc.ExportGeoJSON(&buf, "Unknown"); err != ErrUnknownZone {
+		t.Fatalf("ExportGeoJSON(Unknown): %v", err)
+	}
+}
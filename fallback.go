@@ -0,0 +1,111 @@
+package timezoneLookup
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+)
+
+// Source is the source of the timezone of a Result
+type Source uint8
+
+// Sources
+const (
+	SourceNone     Source = iota // No timezone was found
+	SourcePolygon                // Coordinates are within a timezone polygon
+	SourceNearest                // Nearest timezone polygon fallback
+	SourceNautical               // Nautical timezone fallback
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourcePolygon:
+		return "polygon"
+	case SourceNearest:
+		return "nearest"
+	case SourceNautical:
+		return "nautical"
+	}
+	return "none"
+}
+
+// SetNearestFallback enables a fallback for coordinates that are not within any polygon.
+// Search then returns the timezone of the nearest polygon edge within radius meters,
+// with Result.Approximate set, or ErrNoTimezone when there is none.
+// A radius of 0 disables the fallback.
+func (tzc *Timezonecache) SetNearestFallback(radius float64) {
+	tzc.mu.Lock()
+	tzc.nearest = radius
+	tzc.mu.Unlock()
+}
+
+// SetNauticalFallback enables a fallback for coordinates that are not within any polygon.
+// Search then returns the nautical timezone of the longitude, see NauticalTimezone.
+// The nearest timezone fallback is tried first when both are enabled.
+func (tzc *Timezonecache) SetNauticalFallback(enabled bool) {
+	tzc.mu.Lock()
+	tzc.nautical = enabled
+	tzc.mu.Unlock()
+}
+
+// NauticalTimezone returns the nautical timezone of the longitude as "Etc/GMT+N" or "Etc/GMT-N".
+// Nautical timezones are 15° bands centered on the prime meridian. Following the
+// POSIX convention, the sign is inverted: longitudes east of Greenwich are "Etc/GMT-N".
+func NauticalTimezone(lng float64) string {
+	n := int(math.Round(lng / 15))
+	switch {
+	case n > 0:
+		return "Etc/GMT-" + strconv.Itoa(n)
+	case n < 0:
+		return "Etc/GMT+" + strconv.Itoa(-n)
+	}
+	return "Etc/GMT"
+}
+
+// fallback returns the timezone of coordinates that are not within any polygon.
+func (tzc *Timezonecache) fallback(ctx context.Context, ll geo.LatLng) (Result, error) {
+	if tzc.nearest > 0 {
+		res, err := tzc.searchNearest(ctx, ll)
+		if err == nil || err == ctx.Err() {
+			return res, err
+		}
+	}
+	if tzc.nautical {
+		name := NauticalTimezone(float64(ll.Lng))
+		zone, _ := tzc.zoneID(name)
+		return Result{Name: name, ZoneID: zone, Coordinates: ll, Source: SourceNautical}, nil
+	}
+	if tzc.nearest > 0 {
+		return Result{Coordinates: ll, ZoneID: NoZone}, ErrNoTimezone
+	}
+	return Result{Coordinates: ll, ZoneID: NoZone}, nil
+}
+
+// searchNearest returns the timezone of the nearest polygon within the fallback radius of ll.
+func (tzc *Timezonecache) searchNearest(ctx context.Context, ll geo.LatLng) (Result, error) {
+	zone := NoZone
+	distance := math.Inf(1)
+	nearest := func(id uint) bool {
+		p := geo.NewPolygon()
+		tzc.codec.Decode(&p, tzc.buf(id), tzc.precision)
+		if d := p.DistanceTo(ll); d <= tzc.nearest && d < distance {
+			zone, distance = tzc.zone[id], d
+		}
+		return true
+	}
+	min, max := geo.BoundsAround(ll, tzc.nearest)
+	err := tzc.searchBounds(ctx, min, max, nearest)
+	if min, max, ok := geo.WrappedBoundsAround(ll, tzc.nearest); ok && err == nil {
+		// polygons across the antimeridian
+		err = tzc.searchBounds(ctx, min, max, nearest)
+	}
+	if err != nil {
+		return Result{Coordinates: ll, ZoneID: NoZone}, err
+	}
+	if zone == NoZone {
+		return Result{Coordinates: ll, ZoneID: NoZone}, ErrNoTimezone
+	}
+	return Result{Name: tzc.zones[zone], ZoneID: zone, Coordinates: ll, Distance: distance, Approximate: true, Source: SourceNearest}, nil
+}
@@ -0,0 +1,57 @@
+package timezoneLookup
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestNauticalTimezone(t *testing.T) {
+	tests := []struct {
+		lng  float64
+		want string
+	}{
+		{0, "Etc/GMT"},
+		{7.49, "Etc/GMT"},
+		{-7.49, "Etc/GMT"},
+		{7.5, "Etc/GMT-1"},
+		{-7.5, "Etc/GMT+1"},
+		{15, "Etc/GMT-1"},
+		{-15, "Etc/GMT+1"},
+		{22.5, "Etc/GMT-2"},
+		{-22.49, "Etc/GMT+1"},
+		{172.49, "Etc/GMT-11"},
+		{172.5, "Etc/GMT-12"},
+		{-172.5, "Etc/GMT+12"},
+		{179.99, "Etc/GMT-12"},
+		{180, "Etc/GMT-12"},
+		{-180, "Etc/GMT+12"},
+	}
+	for _, tt := range tests {
+		got := NauticalTimezone(tt.lng)
+		if got != tt.want {
+			t.Errorf("NauticalTimezone(%v) = %q, want %q", tt.lng, got, tt.want)
+			continue
+		}
+		loc, err := time.LoadLocation(got)
+		if err != nil {
+			t.Errorf("NauticalTimezone(%v) = %q: %v", tt.lng, got, err)
+			continue
+		}
+		// the offset of the band centered on lng
+		_, offset := time.Date(2022, 1, 1, 0, 0, 0, 0, loc).Zone()
+		if d := float64(offset)/3600*15 - tt.lng; d > 7.5 || d < -7.5 {
+			t.Errorf("NauticalTimezone(%v) = %q with an offset of %ds", tt.lng, got, offset)
+		}
+	}
+}
+
+func TestNauticalFallback(t *testing.T) {
+	tzc := newTestCache()
+	tzc.SetNauticalFallback(true)
+	res, err := tzc.Search(-50, -100)
+	if err != nil || res.Name != "Etc/GMT+7" || res.Source != SourceNautical || res.ZoneID != NoZone {
+		t.Fatalf("Search(-50, -100) = %q %v zone %d, %v", res.Name, res.Source, res.ZoneID, err)
+	}
+	expectSearch(t, tzc, 5, 5, "A")
+}
@@ -0,0 +1,164 @@
+// Copyright 2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+// CrossesAntimeridian returns true when an edge of a ring of the Polygon
+// crosses the antimeridian. An edge crosses it when its longitudes are more
+// than 180° apart, ex: from 179.5° to -179.5°.
+func (p *Polygon) CrossesAntimeridian() bool {
+	for i := 0; i <= len(p.holes); i++ {
+		ring := p.Ring(i)
+		for j := range ring {
+			if d := ring[j].Lng - ring[(j+1)%len(ring)].Lng; d > 180 || d < -180 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SplitAntimeridian returns the Polygon split at the antimeridian into the
+// polygons east and west of it, each with a bounding box that does not span
+// the world. A Polygon that does not cross the antimeridian is returned as is.
+//
+// The rings are unwrapped to continuous longitudes and clipped at ±180°. A ring
+// that encircles a pole, ex: Antarctica, is closed along the nearest pole.
+func (p Polygon) SplitAntimeridian() []Polygon {
+	if !p.CrossesAntimeridian() {
+		return []Polygon{p}
+	}
+	rings := make([][]LatLng, len(p.holes)+1)
+	rings[0] = unwrapRing(p.Ring(0), minLongitude)
+	if len(rings[0]) == 0 {
+		return nil
+	}
+	// the holes are within the longitudes of the exterior ring
+	lo := rings[0][0].Lng
+	for _, ll := range rings[0] {
+		if ll.Lng < lo {
+			lo = ll.Lng
+		}
+	}
+	for i := 1; i < len(rings); i++ {
+		rings[i] = unwrapRing(p.Ring(i), lo)
+	}
+
+	// west of the antimeridian [-180,180] and east of it [180,540] shifted by -360
+	var pp []Polygon
+	for _, w := range [...]struct{ lo, hi, shift float64 }{{-180, 180, 0}, {180, 540, -360}} {
+		exterior := clipRing(rings[0], w.lo, w.hi, w.shift)
+		if len(exterior) < 4 {
+			continue
+		}
+		part := NewPolygonFromVertices(exterior)
+		for _, r := range rings[1:] {
+			if hole := clipRing(r, w.lo, w.hi, w.shift); len(hole) >= 4 {
+				part.AddHole()
+				for _, ll := range hole {
+					part.AddVertex(ll)
+				}
+			}
+		}
+		pp = append(pp, part)
+	}
+	return pp
+}
+
+// unwrapRing returns a closed copy of ring with longitudes that are continuous
+// across the antimeridian, shifted by a multiple of 360° so that the minimum
+// longitude is within [lo,lo+360).
+func unwrapRing(ring []LatLng, lo float64) []LatLng {
+	if len(ring) == 0 {
+		return nil
+	}
+	v := make([]LatLng, 0, len(ring)+3)
+	v = append(v, ring[0])
+	offset, lat := 0.0, ring[0].Lat
+	for i := 1; i <= len(ring); i++ {
+		ll := ring[i%len(ring)]
+		if i == len(ring) && ll == ring[len(ring)-1] {
+			break // already closed
+		}
+		if d := ll.Lng - ring[i-1].Lng; d > 180 {
+			offset -= 360
+		} else if d < -180 {
+			offset += 360
+		}
+		ll.Lng += offset
+		v = append(v, ll)
+		lat += ll.Lat
+	}
+
+	// a ring around a pole ends 360° from where it started
+	if first, last := v[0], v[len(v)-1]; first.Lng != last.Lng {
+		pole := float64(maxLatitude)
+		if lat < 0 {
+			pole = minLatitude
+		}
+		v = append(v, LatLng{pole, last.Lng}, LatLng{pole, first.Lng}, first)
+	}
+
+	min := v[0].Lng
+	for _, ll := range v {
+		if ll.Lng < min {
+			min = ll.Lng
+		}
+	}
+	shift := 0.0
+	for min+shift < lo {
+		shift += 360
+	}
+	for min+shift >= lo+360 {
+		shift -= 360
+	}
+	if shift != 0 {
+		for i := range v {
+			v[i].Lng += shift
+		}
+	}
+	return v
+}
+
+// clipRing returns the closed ring clipped to the longitudes [lo,hi] with the
+// longitudes shifted by shift, or nil when nothing of the ring is within.
+// Edges are clipped in the planar longitude and latitude of ContainsLatLng.
+func clipRing(ring []LatLng, lo, hi, shift float64) []LatLng {
+	if len(ring) > 1 && ring[0] == ring[len(ring)-1] {
+		ring = ring[:len(ring)-1]
+	}
+	v := clipLng(ring, lo, func(ll LatLng) bool { return ll.Lng >= lo })
+	v = clipLng(v, hi, func(ll LatLng) bool { return ll.Lng <= hi })
+	if len(v) < 3 {
+		return nil
+	}
+	v = append(v, v[0])
+	for i := range v {
+		v[i].Lng += shift
+	}
+	return v
+}
+
+// clipLng clips an open ring to the side of the meridian lng where inside is
+// true (Sutherland–Hodgman).
+func clipLng(ring []LatLng, lng float64, inside func(ll LatLng) bool) []LatLng {
+	var v []LatLng
+	for i, b := range ring {
+		a := ring[(i+len(ring)-1)%len(ring)]
+		switch in, prevIn := inside(b), inside(a); {
+		case in && !prevIn:
+			v = append(v, intersectLng(a, b, lng), b)
+		case in:
+			v = append(v, b)
+		case prevIn:
+			v = append(v, intersectLng(a, b, lng))
+		}
+	}
+	return v
+}
+
+// intersectLng returns the point of the edge a-b at the meridian lng
+func intersectLng(a, b LatLng, lng float64) LatLng {
+	return LatLng{a.Lat + (b.Lat-a.Lat)*(lng-a.Lng)/(b.Lng-a.Lng), lng}
+}
@@ -0,0 +1,171 @@
+// Copyright 2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import "testing"
+
+// ring returns a closed ring of the vertices lat, lng, lat, lng...
+func ring(latlng ...float64) []LatLng {
+	v := make([]LatLng, 0, len(latlng)/2+1)
+	for i := 0; i+1 < len(latlng); i += 2 {
+		v = append(v, LatLng{latlng[i], latlng[i+1]})
+	}
+	return append(v, v[0])
+}
+
+// polygon returns a Polygon with the exterior ring and holes
+func polygon(exterior []LatLng, holes ...[]LatLng) Polygon {
+	p := NewPolygonFromVertices(exterior)
+	for _, h := range holes {
+		p.AddHole()
+		for _, ll := range h {
+			p.AddVertex(ll)
+		}
+	}
+	return p
+}
+
+func TestCrossesAntimeridian(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Polygon
+		want bool
+	}{
+		{"east", polygon(ring(-20, 170, -20, 179, -10, 179, -10, 170)), false},
+		{"west", polygon(ring(-20, -179, -20, -170, -10, -170, -10, -179)), false},
+		{"wide", polygon(ring(-20, -90, -20, 0, -20, 90, -10, 90, -10, 0, -10, -90)), false},
+		{"across", polygon(ring(-20, 175, -20, -175, -10, -175, -10, 175)), true},
+		{"hole", polygon(ring(-20, 170, -20, 180, -10, 180, -10, 170), ring(-16, 179, -16, -179, -14, -179, -14, 179)), true},
+	}
+	for _, tt := range tests {
+		if got := tt.p.CrossesAntimeridian(); got != tt.want {
+			t.Errorf("%s: CrossesAntimeridian() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSplitAntimeridian(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       Polygon
+		bounds  [][2]LatLng // of each part
+		holes   []int       // of each part
+		inside  []LatLng
+		outside []LatLng
+	}{
+		{
+			name:    "not across",
+			p:       polygon(ring(-20, 170, -20, 179, -10, 179, -10, 170)),
+			bounds:  [][2]LatLng{{{-20, 170}, {-10, 179}}},
+			holes:   []int{0},
+			inside:  []LatLng{{-15, 175}},
+			outside: []LatLng{{-15, 179.5}, {-15, -175}},
+		},
+		{
+			name:    "across",
+			p:       polygon(ring(-20, 175, -20, -175, -10, -175, -10, 175)),
+			bounds:  [][2]LatLng{{{-20, 175}, {-10, 180}}, {{-20, -180}, {-10, -175}}},
+			holes:   []int{0, 0},
+			inside:  []LatLng{{-15, 179.99}, {-15, -179.99}, {-15, 176}, {-15, -176}},
+			outside: []LatLng{{-15, 0}, {-15, 174}, {-15, -174}, {-21, 179.99}},
+		},
+		{
+			name:    "hole across",
+			p:       polygon(ring(-30, 170, -30, -170, -10, -170, -10, 170), ring(-22, 178, -22, -178, -18, -178, -18, 178)),
+			bounds:  [][2]LatLng{{{-30, 170}, {-10, 180}}, {{-30, -180}, {-10, -170}}},
+			holes:   []int{1, 1},
+			inside:  []LatLng{{-25, 179.99}, {-25, -179.99}, {-20, 175}, {-20, -175}},
+			outside: []LatLng{{-20, 179.99}, {-20, -179.99}, {-20, 179}, {-20, -179}},
+		},
+		{
+			name:    "hole east",
+			p:       polygon(ring(-30, 170, -30, -170, -10, -170, -10, 170), ring(-22, 172, -22, 174, -18, 174, -18, 172)),
+			bounds:  [][2]LatLng{{{-30, 170}, {-10, 180}}, {{-30, -180}, {-10, -170}}},
+			holes:   []int{1, 0},
+			inside:  []LatLng{{-20, 179.99}, {-20, -179.99}, {-20, 171}},
+			outside: []LatLng{{-20, 173}},
+		},
+		{
+			name:    "south pole",
+			p:       polygon(ring(-80, -120, -80, 0, -80, 120)),
+			bounds:  [][2]LatLng{{{-90, -120}, {-80, 180}}, {{-90, -180}, {-80, -120}}},
+			holes:   []int{0, 0},
+			inside:  []LatLng{{-85, 0}, {-85, 179.99}, {-85, -179.99}, {-85, -150}},
+			outside: []LatLng{{-70, 0}, {-70, 180}},
+		},
+	}
+	for _, tt := range tests {
+		pp := tt.p.SplitAntimeridian()
+		if len(pp) != len(tt.bounds) {
+			t.Errorf("%s: %d polygons, want %d", tt.name, len(pp), len(tt.bounds))
+			continue
+		}
+		for i, p := range pp {
+			if p.Min() != tt.bounds[i][0] || p.Max() != tt.bounds[i][1] {
+				t.Errorf("%s: polygon %d bounds %v %v, want %v %v", tt.name, i, p.Min(), p.Max(), tt.bounds[i][0], tt.bounds[i][1])
+			}
+			if p.Holes() != tt.holes[i] {
+				t.Errorf("%s: polygon %d has %d holes, want %d", tt.name, i, p.Holes(), tt.holes[i])
+			}
+			if p.Min().Lng == -180 && p.Max().Lng == 180 {
+				t.Errorf("%s: polygon %d spans the world", tt.name, i)
+			}
+		}
+		for _, ll := range tt.inside {
+			if n := containing(pp, ll); n != 1 {
+				t.Errorf("%s: %v is within %d polygons, want 1", tt.name, ll, n)
+			}
+		}
+		for _, ll := range tt.outside {
+			if n := containing(pp, ll); n != 0 {
+				t.Errorf("%s: %v is within %d polygons, want 0", tt.name, ll, n)
+			}
+		}
+	}
+}
+
+// containing returns the number of polygons of pp that contain ll
+func containing(pp []Polygon, ll LatLng) (n int) {
+	for i := range pp {
+		if pp[i].ContainsLatLng(ll) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestWrappedBoundsAround(t *testing.T) {
+	const radius = 10000 // 0.09° of latitude, 0.094° of longitude at 17°
+	tests := []struct {
+		ll       LatLng
+		ok       bool
+		min, max float64 // longitudes
+	}{
+		{LatLng{-17, 179.99}, true, -180, -179.916},
+		{LatLng{-17, -179.99}, true, 179.916, 180},
+		{LatLng{-17, 180}, true, -180, -179.906},
+		{LatLng{-17, -180}, true, 179.906, 180},
+		{LatLng{-17, 179}, false, 0, 0},
+		{LatLng{-17, 0}, false, 0, 0},
+		{LatLng{89.99, 179.99}, false, 0, 0}, // spans all longitudes
+	}
+	for _, tt := range tests {
+		min, max, ok := WrappedBoundsAround(tt.ll, radius)
+		if ok != tt.ok {
+			t.Errorf("WrappedBoundsAround(%v) ok = %v, want %v", tt.ll, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		// the longitudes of the wrapped side of the bounding box, within 0.001°
+		if min.Lng < tt.min-0.001 || min.Lng > tt.min+0.001 || max.Lng < tt.max-0.001 || max.Lng > tt.max+0.001 {
+			t.Errorf("WrappedBoundsAround(%v) longitudes %v to %v, want %v to %v", tt.ll, min.Lng, max.Lng, tt.min, tt.max)
+		}
+		if min.Lat >= tt.ll.Lat || max.Lat <= tt.ll.Lat {
+			t.Errorf("WrappedBoundsAround(%v) latitudes %v to %v", tt.ll, min.Lat, max.Lat)
+		}
+	}
+}
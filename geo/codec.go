@@ -0,0 +1,153 @@
+// Copyright 2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+var (
+	ErrCodecNotValid = errors.New("error codec is not valid")
+)
+
+// Codec is the storage encoding of an encoded Polygon
+type Codec uint8
+
+const (
+	// Raw encodes every vertex in Precision.VertexSize bytes, see Polygon.Encode.
+	Raw Codec = iota
+
+	// Delta encodes every vertex as the fixed-point difference from the previous
+	// vertex in zigzag varints, see Polygon.EncodeDelta.
+	Delta
+)
+
+// String returns the name of the codec
+func (c Codec) String() string {
+	switch c {
+	case Raw:
+		return "raw"
+	case Delta:
+		return "delta"
+	}
+	return "unknown"
+}
+
+// Valid returns true when c is a known Codec
+func (c Codec) Valid() bool {
+	return c <= Delta
+}
+
+// ParseCodec returns the Codec with the name s, see Codec.String.
+func ParseCodec(s string) (Codec, error) {
+	for c := Raw; c.Valid(); c++ {
+		if c.String() == s {
+			return c, nil
+		}
+	}
+	return 0, ErrCodecNotValid
+}
+
+// Encode encodes p with the codec and precision prec
+func (c Codec) Encode(p Polygon, prec Precision) []byte {
+	if c == Delta {
+		return p.EncodeDelta(prec)
+	}
+	return p.Encode(prec)
+}
+
+// Decode decodes src, encoded with the codec and precision prec, into p.
+// The bounding box is not updated, see Polygon.Decode.
+func (c Codec) Decode(p *Polygon, src []byte, prec Precision) {
+	if c == Delta {
+		p.DecodeDelta(src, prec)
+		return
+	}
+	p.Decode(src, prec)
+}
+
+// DecodePolygon returns a new Polygon decoded from b, encoded with the codec and precision prec.
+// updates the polygon's boundingbox.
+func (c Codec) DecodePolygon(b []byte, prec Precision) Polygon {
+	p := NewPolygon()
+	c.Decode(&p, b, prec)
+	p.UpdateBoundingBox()
+	return p
+}
+
+// EncodeDelta encodes the Polygon with the Delta codec as:
+// [uvarint]holes [uvarint*holes]hole start index [2*varint*vertices]vertices
+//
+// The latitude and longitude of a vertex are the zigzag varint difference from
+// the previous vertex in the fixed-point degrees of precision prec: 1e-7 degrees
+// for Float32, 1e-9 for Float64 and 1e-6 for MicroDegrees. Consecutive vertices
+// are close together so that most differences take one or two bytes.
+func (p Polygon) EncodeDelta(prec Precision) []byte {
+	scale := prec.scale()
+	if scale == 0 {
+		return nil
+	}
+	var tmp [binary.MaxVarintLen64]byte
+	b := make([]byte, 0, binary.MaxVarintLen32*(1+len(p.holes))+4*len(p.v))
+	b = append(b, tmp[:binary.PutUvarint(tmp[:], uint64(len(p.holes)))]...)
+	for _, h := range p.holes {
+		b = append(b, tmp[:binary.PutUvarint(tmp[:], uint64(h))]...)
+	}
+	var lat, lng int64
+	for _, ll := range p.v {
+		la, ln := int64(math.Round(ll.Lat*scale)), int64(math.Round(ll.Lng*scale))
+		b = append(b, tmp[:binary.PutVarint(tmp[:], la-lat)]...)
+		b = append(b, tmp[:binary.PutVarint(tmp[:], ln-lng)]...)
+		lat, lng = la, ln
+	}
+	return b
+}
+
+// DecodeDelta decodes a Polygon encoded with EncodeDelta and precision prec, see Decode.
+func (p *Polygon) DecodeDelta(src []byte, prec Precision) {
+	p.v, p.holes = p.v[:0], p.holes[:0]
+	scale := prec.scale()
+	holes, n := binary.Uvarint(src)
+	// every hole start index is at least one byte long
+	if n <= 0 || scale == 0 || holes > uint64(len(src)-n) {
+		return
+	}
+	src = src[n:]
+	for i := uint64(0); i < holes; i++ {
+		h, n := binary.Uvarint(src)
+		if n <= 0 || h > math.MaxUint32 {
+			p.holes = p.holes[:0]
+			return
+		}
+		p.holes = append(p.holes, uint32(h))
+		src = src[n:]
+	}
+	var lat, lng int64
+	for len(src) > 0 {
+		dLat, n := binary.Varint(src)
+		if n <= 0 {
+			p.v, p.holes = p.v[:0], p.holes[:0]
+			return
+		}
+		dLng, m := binary.Varint(src[n:])
+		if m <= 0 {
+			p.v, p.holes = p.v[:0], p.holes[:0]
+			return
+		}
+		src = src[n+m:]
+		lat, lng = lat+dLat, lng+dLng
+		p.v = append(p.v, LatLng{Lat: float64(lat) / scale, Lng: float64(lng) / scale})
+	}
+	var prev uint32
+	for _, h := range p.holes {
+		if h < prev || int(h) > len(p.v) {
+			p.v, p.holes = p.v[:0], p.holes[:0]
+			return
+		}
+		prev = h
+	}
+}
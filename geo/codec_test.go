@@ -0,0 +1,85 @@
+// Copyright 2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+// testPolygon returns a Polygon with two holes, vertices at the bounds of the
+// valid coordinates and long edges.
+func testPolygon() Polygon {
+	return polygon(
+		ring(-90, -180, -90, 180, 90, 180, 90, -180, 0.123456789, -0.987654321),
+		ring(-10.1234567, -10.7654321, -10.1234567, 10.1, 10.1, 10.1, 10.1, -10.7654321),
+		ring(45.000000001, 120.5, 45.5, 120.5, 45.5, 121.000000001),
+	)
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	p := testPolygon()
+	for prec := Float32; prec.Valid(); prec++ {
+		for codec := Raw; codec.Valid(); codec++ {
+			b := codec.Encode(p, prec)
+			got := codec.DecodePolygon(b, prec)
+			if got.Holes() != p.Holes() || got.Length() != p.Length() {
+				t.Errorf("%v %v: %d vertices and %d holes, want %d and %d", prec, codec, got.Length(), got.Holes(), p.Length(), p.Holes())
+				continue
+			}
+			for i := 0; i <= p.Holes(); i++ {
+				want, ring := p.Ring(i), got.Ring(i)
+				if len(ring) != len(want) {
+					t.Errorf("%v %v: ring %d has %d vertices, want %d", prec, codec, i, len(ring), len(want))
+					continue
+				}
+				for j, ll := range ring {
+					if !roundTripped(ll, want[j], prec, codec) {
+						t.Errorf("%v %v: ring %d vertex %d is %v, want %v", prec, codec, i, j, ll, want[j])
+					}
+				}
+			}
+			// decoded vertices encode to the same bytes
+			if b2 := codec.Encode(got, prec); string(b2) != string(b) {
+				t.Errorf("%v %v: encoding of the decoded polygon differs", prec, codec)
+			}
+			if got.Min() != NewLatLng(-90, -180) || got.Max() != NewLatLng(90, 180) {
+				t.Errorf("%v %v: bounds %v %v", prec, codec, got.Min(), got.Max())
+			}
+		}
+	}
+}
+
+// roundTripped returns true when ll is want encoded with codec and prec. Raw
+// vertices are quantized to the precision, Delta vertices are rounded to the
+// fixed-point degrees of the precision.
+func roundTripped(ll, want LatLng, prec Precision, codec Codec) bool {
+	if codec == Raw {
+		return ll == prec.Quantize(want)
+	}
+	scale := prec.scale()
+	return ll.Lat == math.Round(want.Lat*scale)/scale && ll.Lng == math.Round(want.Lng*scale)/scale
+}
+
+func TestDecodeDeltaCorrupt(t *testing.T) {
+	b := testPolygon().EncodeDelta(MicroDegrees)
+	tests := []struct {
+		name string
+		b    []byte
+	}{
+		{"empty", nil},
+		{"truncated varint", b[:len(b)-1]},
+		{"truncated holes", b[:2]},
+		{"hole after the vertices", append([]byte{1, 100}, b[3:]...)},
+		{"too many holes", append([]byte{0xff, 0xff, 0x03}, b[1:]...)},
+	}
+	for _, tt := range tests {
+		p := NewPolygon()
+		p.DecodeDelta(tt.b, MicroDegrees)
+		if p.Length() != 0 || p.Holes() != 0 {
+			t.Errorf("%s: decoded %d vertices and %d holes", tt.name, p.Length(), p.Holes())
+		}
+	}
+}
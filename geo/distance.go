@@ -0,0 +1,122 @@
+// Copyright 2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import "math"
+
+const (
+	// EarthRadius is the mean radius of the Earth in meters
+	EarthRadius = 6371008.8
+
+	// MetersPerDegree is the length in meters of one degree of latitude
+	MetersPerDegree = EarthRadius * math.Pi / 180
+)
+
+// BoundsAround returns the bounding box of the circle with a radius in meters around ll.
+// The bounds are clamped to the valid latitude and longitude range.
+func BoundsAround(ll LatLng, radius float64) (min, max LatLng) {
+	dLat := radius / MetersPerDegree
+	dLng := 360.0
+	if c := math.Cos(radians(ll.Lat)); c > 0 && dLat/c < 180 {
+		dLng = dLat / c
+	}
+	min = LatLng{math.Max(ll.Lat-dLat, minLatitude), math.Max(ll.Lng-dLng, minLongitude)}
+	max = LatLng{math.Min(ll.Lat+dLat, maxLatitude), math.Min(ll.Lng+dLng, maxLongitude)}
+	return min, max
+}
+
+// WrappedBoundsAround returns the part of the bounding box of the circle with a
+// radius in meters around ll that is across the antimeridian, wrapped to the
+// other side of it. ok is false when the circle does not cross the antimeridian.
+func WrappedBoundsAround(ll LatLng, radius float64) (min, max LatLng, ok bool) {
+	dLat := radius / MetersPerDegree
+	dLng := 360.0
+	if c := math.Cos(radians(ll.Lat)); c > 0 && dLat/c < 180 {
+		dLng = dLat / c
+	}
+	min = LatLng{math.Max(ll.Lat-dLat, minLatitude), minLongitude}
+	max = LatLng{math.Min(ll.Lat+dLat, maxLatitude), maxLongitude}
+	switch {
+	case dLng >= 180:
+		return min, max, false // the bounding box already spans all longitudes
+	case ll.Lng+dLng > maxLongitude:
+		max.Lng = ll.Lng + dLng - 360
+	case ll.Lng-dLng < minLongitude:
+		min.Lng = ll.Lng - dLng + 360
+	default:
+		return min, max, false
+	}
+	return min, max, true
+}
+
+// DistanceTo returns the approximate distance in meters from ll to the nearest
+// edge of the Polygon, including the edges of its holes. Distances are computed
+// on an equirectangular projection centered on ll and are accurate for distances
+// well below the radius of the Earth.
+func (p *Polygon) DistanceTo(ll LatLng) float64 {
+	d := math.Inf(1)
+	kx := math.Cos(radians(ll.Lat)) * MetersPerDegree
+	for i := 0; i <= len(p.holes); i++ {
+		ring := p.Ring(i)
+		for j := range ring {
+			a, b := ring[j], ring[(j+1)%len(ring)]
+			ax, ay := lngDelta(ll.Lng, a.Lng)*kx, (a.Lat-ll.Lat)*MetersPerDegree
+			bx, by := lngDelta(ll.Lng, b.Lng)*kx, (b.Lat-ll.Lat)*MetersPerDegree
+			if s := distanceToSegment(ax, ay, bx, by); s < d {
+				d = s
+			}
+		}
+	}
+	return d
+}
+
+// distanceToSegment returns the distance from the origin to the segment a-b
+func distanceToSegment(ax, ay, bx, by float64) float64 {
+	dx, dy := bx-ax, by-ay
+	t := 0.0
+	if l := dx*dx + dy*dy; l > 0 {
+		t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/l))
+	}
+	return math.Hypot(ax+t*dx, ay+t*dy)
+}
+
+// lngDelta returns the difference in degrees from lng to lng2 wrapped to [-180,180]
+func lngDelta(lng, lng2 float64) float64 {
+	d := lng2 - lng
+	if d > 180 {
+		d -= 360
+	} else if d < -180 {
+		d += 360
+	}
+	return d
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// Area returns the approximate geodesic area of the Polygon in square meters.
+// The area of holes is excluded.
+func (p *Polygon) Area() float64 {
+	area := ringArea(p.Ring(0))
+	for i := 1; i <= len(p.holes); i++ {
+		area -= ringArea(p.Ring(i))
+	}
+	return math.Max(area, 0)
+}
+
+// ringArea returns the area of a ring on a sphere in square meters.
+// reference: Chamberlain & Duquette, "Some Algorithms for Polygons on a Sphere", 2007
+func ringArea(ring []LatLng) float64 {
+	if len(ring) < 3 {
+		return 0
+	}
+	var sum float64
+	for i := range ring {
+		a, b := ring[i], ring[(i+1)%len(ring)]
+		sum += lngDelta(a.Lng, b.Lng) * math.Pi / 180 * (2 + math.Sin(radians(a.Lat)) + math.Sin(radians(b.Lat)))
+	}
+	return math.Abs(sum * EarthRadius * EarthRadius / 2)
+}
@@ -13,11 +13,11 @@ const (
 
 // LatLng represents Latitude and Longitude in degree format
 type LatLng struct {
-	Lat, Lng float32
+	Lat, Lng float64
 }
 
 func (ll LatLng) toFloat32() [2]float32 {
-	return [2]float32{ll.Lat, ll.Lng}
+	return [2]float32{float32(ll.Lat), float32(ll.Lng)}
 }
 
 // Valid returns true of LatLng is with the bounds of minLatitude/maxLatitude and minLongitude/maxLongitude
@@ -27,7 +27,7 @@ func (ll LatLng) Valid() bool {
 
 // NewLatLng returns a new LatLng with the given latitude and longitude
 func NewLatLng(latitude, longitude float64) LatLng {
-	return LatLng{float32(latitude), float32(longitude)}
+	return LatLng{latitude, longitude}
 }
 
 // SearchLatLng searchs the RTree for the given LatLng combination
@@ -35,10 +35,20 @@ func (tr *RTree) SearchLatLng(ll LatLng, iter func(min, max LatLng, value interf
 	tr.searchLatLng(rect{min: ll.toFloat32(), max: ll.toFloat32()}, iter)
 }
 
-// InsertPolygon data into tree
+// SearchBounds searchs the RTree for items that intersect the bounds of min and max
+func (tr *RTree) SearchBounds(min, max LatLng, iter func(min, max LatLng, value interface{}) bool) {
+	tr.searchLatLng(rect{
+		min: [2]float32{float32Down(min.Lat), float32Down(min.Lng)},
+		max: [2]float32{float32Up(max.Lat), float32Up(max.Lng)},
+	}, iter)
+}
+
+// InsertPolygon data into tree. The bounds of the Polygon are rounded outwards to float32.
 func (tr *RTree) InsertPolygon(p Polygon, value interface{}) {
 	var item rect
-	fit(p.min.toFloat32(), p.max.toFloat32(), value, &item)
+	min := [2]float32{float32Down(p.min.Lat), float32Down(p.min.Lng)}
+	max := [2]float32{float32Up(p.max.Lat), float32Up(p.max.Lng)}
+	fit(min, max, value, &item)
 	tr.insert(&item)
 }
 
@@ -62,7 +72,8 @@ func (r *rect) searchLatLng(
 	if height == 0 {
 		for i := 0; i < n.count; i++ {
 			if target.intersects(&n.rects[i]) {
-				if !iter(LatLng{n.rects[i].min[0], n.rects[i].min[1]}, LatLng{n.rects[i].max[0], n.rects[i].max[1]}, n.rects[i].data) {
+				min, max := n.rects[i].min, n.rects[i].max
+				if !iter(LatLng{float64(min[0]), float64(min[1])}, LatLng{float64(max[0]), float64(max[1])}, n.rects[i].data) {
 					return false
 				}
 			}
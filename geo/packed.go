@@ -0,0 +1,307 @@
+// Copyright 2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+)
+
+// DefaultNodeSize is the number of children of each node in a PackedRTree
+const DefaultNodeSize = 16
+
+const (
+	packedHeaderSize = 12
+	packedNodeSize   = 20
+)
+
+var (
+	ErrPackedRTreeNotValid = errors.New("error packed rtree is not valid")
+)
+
+// PackedItem is an item of a PackedRTree.
+type PackedItem struct {
+	Min, Max LatLng
+	ID       uint32
+}
+
+// PackedRTree is a static RTree that is bulk-loaded using Sort-Tile-Recursive
+// packing and searched directly from its serialized form. Searches do not
+// allocate and the tree can be backed by memory mapped data.
+//
+// Layout:
+// [4]items [4]nodes [2]nodesize [2]levels [4*levels]levelend [20*nodes]node
+//
+// Node:
+// [4]minLat [4]minLng [4]maxLat [4]maxLng [4]index
+//
+// Bounds are stored as float32 and rounded outwards, so that no item is missed.
+//
+// Level 0 contains the items, where index is the item ID. For all other levels
+// index is the position of the node's first child in the level below.
+type PackedRTree struct {
+	b []byte
+}
+
+// PackRTree bulk-loads items into a PackedRTree and returns its serialized form.
+// The items are sorted in place.
+func PackRTree(items []PackedItem, nodeSize int) []byte {
+	if nodeSize < 2 {
+		nodeSize = DefaultNodeSize
+	}
+	sortTileRecursive(items, nodeSize)
+
+	// level ends
+	levels := []int{len(items)}
+	count, nodes := len(items), len(items)
+	for count > 1 || (len(levels) == 1 && count > 0) {
+		count = (count + nodeSize - 1) / nodeSize
+		nodes += count
+		levels = append(levels, nodes)
+	}
+	if len(items) == 0 {
+		levels = levels[:0]
+	}
+
+	b := make([]byte, packedHeaderSize+4*len(levels)+packedNodeSize*nodes)
+	binary.LittleEndian.PutUint32(b[0:4], uint32(len(items)))
+	binary.LittleEndian.PutUint32(b[4:8], uint32(nodes))
+	binary.LittleEndian.PutUint16(b[8:10], uint16(nodeSize))
+	binary.LittleEndian.PutUint16(b[10:12], uint16(len(levels)))
+	for i, end := range levels {
+		binary.LittleEndian.PutUint32(b[packedHeaderSize+4*i:], uint32(end))
+	}
+	t := PackedRTree{b: b}
+	for i, item := range items {
+		t.putNode(i, item.Min, item.Max, item.ID)
+	}
+	start := 0
+	for l := 1; l < len(levels); l++ {
+		end, pos := levels[l-1], levels[l-1]
+		for i := start; i < end; i += nodeSize {
+			min, max, _ := t.node(i)
+			for j := i + 1; j < i+nodeSize && j < end; j++ {
+				cmin, cmax, _ := t.node(j)
+				min, max = ExpandBounds(min, max, cmin, cmax)
+			}
+			t.putNode(pos, min, max, uint32(i))
+			pos++
+		}
+		start = end
+	}
+	return b
+}
+
+// sortTileRecursive sorts items into vertical slices by longitude and
+// each slice by latitude, so that consecutive items are grouped into nodes.
+func sortTileRecursive(items []PackedItem, nodeSize int) {
+	center := func(item PackedItem) (lat, lng float64) {
+		return (float64(item.Min.Lat) + float64(item.Max.Lat)) / 2, (float64(item.Min.Lng) + float64(item.Max.Lng)) / 2
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		_, a := center(items[i])
+		_, b := center(items[j])
+		return a < b
+	})
+	leaves := (len(items) + nodeSize - 1) / nodeSize
+	sliceSize := int(math.Ceil(math.Sqrt(float64(leaves)))) * nodeSize
+	if sliceSize == 0 {
+		return
+	}
+	for i := 0; i < len(items); i += sliceSize {
+		end := i + sliceSize
+		if end > len(items) {
+			end = len(items)
+		}
+		s := items[i:end]
+		sort.SliceStable(s, func(i, j int) bool {
+			a, _ := center(s[i])
+			b, _ := center(s[j])
+			return a < b
+		})
+	}
+}
+
+// ExpandBounds returns the bounds of min and max expanded to include the bounds of bmin and bmax.
+func ExpandBounds(min, max, bmin, bmax LatLng) (LatLng, LatLng) {
+	if bmin.Lat < min.Lat {
+		min.Lat = bmin.Lat
+	}
+	if bmin.Lng < min.Lng {
+		min.Lng = bmin.Lng
+	}
+	if bmax.Lat > max.Lat {
+		max.Lat = bmax.Lat
+	}
+	if bmax.Lng > max.Lng {
+		max.Lng = bmax.Lng
+	}
+	return min, max
+}
+
+// NewPackedRTree returns a PackedRTree from its serialized form. The tree references b directly.
+func NewPackedRTree(b []byte) (PackedRTree, error) {
+	if len(b) < packedHeaderSize {
+		return PackedRTree{}, ErrPackedRTreeNotValid
+	}
+	t := PackedRTree{b: b}
+	levels := t.levels()
+	nodes := int(binary.LittleEndian.Uint32(b[4:8]))
+	if len(b) != packedHeaderSize+4*levels+packedNodeSize*nodes {
+		return PackedRTree{}, ErrPackedRTreeNotValid
+	}
+	if t.Len() == 0 {
+		if levels != 0 {
+			return PackedRTree{}, ErrPackedRTreeNotValid
+		}
+		return t, nil
+	}
+	if levels < 2 || t.nodeSize() < 2 {
+		return PackedRTree{}, ErrPackedRTreeNotValid
+	}
+	prev := 0
+	for l := 0; l < levels; l++ {
+		end := t.levelEnd(l)
+		if end <= prev || end > nodes {
+			return PackedRTree{}, ErrPackedRTreeNotValid
+		}
+		prev = end
+	}
+	if t.levelEnd(0) != t.Len() || prev != nodes || prev-t.levelEnd(levels-2) != 1 {
+		return PackedRTree{}, ErrPackedRTreeNotValid
+	}
+	return t, nil
+}
+
+// Bytes returns the serialized form of the PackedRTree
+func (t PackedRTree) Bytes() []byte {
+	return t.b
+}
+
+// Len returns the number of items in the PackedRTree
+func (t PackedRTree) Len() int {
+	if len(t.b) < packedHeaderSize {
+		return 0
+	}
+	return int(binary.LittleEndian.Uint32(t.b[0:4]))
+}
+
+func (t PackedRTree) nodeSize() int {
+	return int(binary.LittleEndian.Uint16(t.b[8:10]))
+}
+
+func (t PackedRTree) levels() int {
+	return int(binary.LittleEndian.Uint16(t.b[10:12]))
+}
+
+func (t PackedRTree) levelEnd(level int) int {
+	if level < 0 {
+		return 0
+	}
+	return int(binary.LittleEndian.Uint32(t.b[packedHeaderSize+4*level:]))
+}
+
+func (t PackedRTree) nodeOffset(i int) int {
+	return packedHeaderSize + 4*t.levels() + packedNodeSize*i
+}
+
+func (t PackedRTree) node(i int) (min, max LatLng, index uint32) {
+	b := t.b[t.nodeOffset(i):]
+	b = b[:packedNodeSize]
+	min.Lat = float64(math.Float32frombits(binary.LittleEndian.Uint32(b[0:4])))
+	min.Lng = float64(math.Float32frombits(binary.LittleEndian.Uint32(b[4:8])))
+	max.Lat = float64(math.Float32frombits(binary.LittleEndian.Uint32(b[8:12])))
+	max.Lng = float64(math.Float32frombits(binary.LittleEndian.Uint32(b[12:16])))
+	return min, max, binary.LittleEndian.Uint32(b[16:20])
+}
+
+func (t PackedRTree) putNode(i int, min, max LatLng, index uint32) {
+	b := t.b[t.nodeOffset(i):]
+	b = b[:packedNodeSize]
+	binary.LittleEndian.PutUint32(b[0:4], math.Float32bits(float32Down(min.Lat)))
+	binary.LittleEndian.PutUint32(b[4:8], math.Float32bits(float32Down(min.Lng)))
+	binary.LittleEndian.PutUint32(b[8:12], math.Float32bits(float32Up(max.Lat)))
+	binary.LittleEndian.PutUint32(b[12:16], math.Float32bits(float32Up(max.Lng)))
+	binary.LittleEndian.PutUint32(b[16:20], index)
+}
+
+// SearchLatLng searches the PackedRTree for items that intersect the given LatLng.
+// Searching stops when iter returns false.
+func (t PackedRTree) SearchLatLng(ll LatLng, iter func(id uint32) bool) {
+	t.Search(ll, ll, iter)
+}
+
+// Search searches the PackedRTree for items that intersect the bounds of min and max.
+// Searching stops when iter returns false.
+func (t PackedRTree) Search(min, max LatLng, iter func(id uint32) bool) {
+	t.SearchContext(context.Background(), min, max, iter)
+}
+
+// SearchContext searches the PackedRTree for items that intersect the bounds of min and max.
+// Searching stops when iter returns false or when ctx is done, in which case ctx.Err() is returned.
+func (t PackedRTree) SearchContext(ctx context.Context, min, max LatLng, iter func(id uint32) bool) error {
+	levels := t.levels()
+	if t.Len() == 0 || levels == 0 {
+		return nil
+	}
+	root := t.levelEnd(levels-1) - 1
+	nmin, nmax, _ := t.node(root)
+	if intersectsBounds(min, max, nmin, nmax) {
+		t.search(ctx, root, levels-1, min, max, iter)
+	}
+	return ctx.Err()
+}
+
+func (t PackedRTree) search(ctx context.Context, i, level int, min, max LatLng, iter func(id uint32) bool) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	_, _, index := t.node(i)
+	first, end := int(index), int(index)+t.nodeSize()
+	if first < t.levelEnd(level-2) {
+		return true
+	}
+	if levelEnd := t.levelEnd(level - 1); end > levelEnd {
+		end = levelEnd
+	}
+	for j := first; j < end; j++ {
+		cmin, cmax, index := t.node(j)
+		if !intersectsBounds(min, max, cmin, cmax) {
+			continue
+		}
+		if level == 1 {
+			if !iter(index) {
+				return false
+			}
+		} else if !t.search(ctx, j, level-1, min, max, iter) {
+			return false
+		}
+	}
+	return true
+}
+
+// Scan iterates through all items of the PackedRTree.
+// Scanning stops when iter returns false.
+func (t PackedRTree) Scan(iter func(min, max LatLng, id uint32) bool) {
+	for i := 0; i < t.Len(); i++ {
+		min, max, id := t.node(i)
+		if !iter(min, max, id) {
+			return
+		}
+	}
+}
+
+func intersectsBounds(min, max, bmin, bmax LatLng) bool {
+	if bmin.Lat > max.Lat || bmax.Lat < min.Lat {
+		return false
+	}
+	if bmin.Lng > max.Lng || bmax.Lng < min.Lng {
+		return false
+	}
+	return true
+}
@@ -5,15 +5,18 @@
 package geo
 
 import (
-	"reflect"
-	"unsafe"
+	"encoding/binary"
 )
 
 // Polygon represents a closed Polygon of vertices when
 // the first and last vertices are equal.
+//
+// The first ring of vertices is the exterior ring. Any following
+// rings are interior rings (holes) that are excluded from the Polygon.
 type Polygon struct {
 	min, max LatLng   // min and man LatLng
 	v        []LatLng // Vertices
+	holes    []uint32 // Index of the first vertex of each hole
 }
 
 // NewPolygon returns a new empty Polygon
@@ -33,9 +36,17 @@ func NewPolygonFromVertices(s []LatLng) Polygon {
 	return p
 }
 
+// NewPolygonFromBytes returns a new Polygon decoded from b, encoded with ToByteSlice.
+// updates the polygon's boundingbox.
 func NewPolygonFromBytes(b []byte) Polygon {
+	return DecodePolygon(b, Float32)
+}
+
+// DecodePolygon returns a new Polygon decoded from b, encoded with Encode and precision prec.
+// updates the polygon's boundingbox.
+func DecodePolygon(b []byte, prec Precision) Polygon {
 	p := NewPolygon()
-	p.v = toLatLngSlice(b)
+	p.Decode(b, prec)
 	p.UpdateBoundingBox()
 	return p
 }
@@ -63,6 +74,33 @@ func (p *Polygon) Length() int {
 	return len(p.v)
 }
 
+// Holes returns the number of interior rings (holes) in the Polygon
+func (p *Polygon) Holes() int {
+	return len(p.holes)
+}
+
+// Ring returns the vertices of ring i. Ring 0 is the exterior ring,
+// rings 1 through Holes() are the interior rings.
+func (p *Polygon) Ring(i int) []LatLng {
+	if i < 0 || i > len(p.holes) {
+		return nil
+	}
+	start, end := 0, len(p.v)
+	if i > 0 {
+		start = int(p.holes[i-1])
+	}
+	if i < len(p.holes) {
+		end = int(p.holes[i])
+	}
+	return p.v[start:end]
+}
+
+// AddHole starts a new interior ring (hole). Vertices added afterwards
+// with Add or AddVertex belong to the new hole.
+func (p *Polygon) AddHole() {
+	p.holes = append(p.holes, uint32(len(p.v)))
+}
+
 // Max returns the bottom-left coordinate of the Polygon.
 // Correspoinding to the minimum latitide and longitude values contained.
 func (p *Polygon) Min() LatLng {
@@ -79,7 +117,7 @@ func (p *Polygon) Max() LatLng {
 // Maximum and minimum latitude is -90 and +90 respectively.
 // Maximum and minimum longitude is -180 and +180 respectively.
 func (p *Polygon) Add(latitude, longitude float64) {
-	p.AddVertex(LatLng{float32(latitude), float32(longitude)})
+	p.AddVertex(LatLng{latitude, longitude})
 }
 
 // AddVertex adds a LatLng Vertex to the Polygon. Updates polygon bounds with new Vertex.
@@ -97,48 +135,174 @@ func (p *Polygon) UpdateBoundingBox() {
 	}
 }
 
+// ContainsLatLng returns true when the query LatLng is within the exterior
+// ring of the Polygon and not within any of its holes. A query exactly on an
+// edge or vertex may be either, see ContainsLatLngWithBoundary.
 func (p *Polygon) ContainsLatLng(query LatLng) bool {
-	if len(p.v) < 3 {
+	if !ringContains(p.Ring(0), query) {
 		return false
 	}
-	in := rayIntersectsSegment(query, p.v[len(p.v)-1], p.v[0])
-	for i := 1; i < len(p.v); i++ {
-		if rayIntersectsSegment(query, p.v[i-1], p.v[i]) {
+	for i := 1; i <= len(p.holes); i++ {
+		if ringContains(p.Ring(i), query) {
+			return false
+		}
+	}
+	return true
+}
+
+func ringContains(ring []LatLng, query LatLng) bool {
+	if len(ring) < 3 {
+		return false
+	}
+	in := rayIntersectsSegment(query, ring[len(ring)-1], ring[0])
+	for i := 1; i < len(ring); i++ {
+		if rayIntersectsSegment(query, ring[i-1], ring[i]) {
 			in = !in
 		}
 	}
 	return in
 }
 
+// rayIntersectsSegment returns true when the ray from p towards increasing
+// latitude crosses the segment a-b. The segment is ordered first, so that an
+// edge shared by two rings in opposite directions gives the same result.
 func rayIntersectsSegment(p, a, b LatLng) bool {
+	if lessLatLng(b, a) {
+		a, b = b, a
+	}
 	return (a.Lng > p.Lng) != (b.Lng > p.Lng) &&
 		p.Lat < (b.Lat-a.Lat)*(p.Lng-a.Lng)/(b.Lng-a.Lng)+a.Lat
 }
 
-// reference: https://go101.org/article/unsafe.html
-func toByteSlice(b []LatLng) []byte {
-	var bs []byte
-	hdr := (*reflect.SliceHeader)(unsafe.Pointer(&bs))
-	hdr.Len = len(b) * 8
-	hdr.Cap = hdr.Len
-	hdr.Data = uintptr(unsafe.Pointer(&b[0]))
-	return bs
+// Location is the location of a LatLng relative to a Polygon
+type Location uint8
+
+// Locations
+const (
+	Outside Location = iota // Outside of the exterior ring or within a hole
+	Inside                  // Within the exterior ring and not within a hole
+	OnEdge                  // Exactly on an edge or vertex of the exterior ring or of a hole
+)
+
+// String returns the name of the location
+func (l Location) String() string {
+	switch l {
+	case Outside:
+		return "outside"
+	case Inside:
+		return "inside"
+	case OnEdge:
+		return "on edge"
+	}
+	return "unknown"
+}
+
+// ContainsLatLngWithBoundary returns whether the query LatLng is Inside, Outside
+// or OnEdge of the Polygon. A query is OnEdge when it is on the line between two
+// consecutive vertices of a ring, including the vertices, as computed in float64
+// degrees. Otherwise the result is the same as ContainsLatLng. The result does not
+// depend on the direction of the edges, so that a query on an edge shared by two
+// polygons is OnEdge of both, or Inside exactly one of them.
+func (p *Polygon) ContainsLatLngWithBoundary(query LatLng) Location {
+	if l := ringLocation(p.Ring(0), query); l != Inside {
+		return l
+	}
+	for i := 1; i <= len(p.holes); i++ {
+		switch ringLocation(p.Ring(i), query) {
+		case OnEdge:
+			return OnEdge
+		case Inside:
+			return Outside
+		}
+	}
+	return Inside
 }
 
-// reference: https://go101.org/article/unsafe.html
-func toLatLngSlice(b []byte) (result []LatLng) {
-	var lls []LatLng
-	hdr := (*reflect.SliceHeader)(unsafe.Pointer(&lls))
-	hdr.Len = len(b) / 8
-	hdr.Cap = hdr.Len
-	hdr.Data = uintptr(unsafe.Pointer(&b[0]))
-	return lls
+func ringLocation(ring []LatLng, query LatLng) Location {
+	if len(ring) < 3 {
+		return Outside
+	}
+	in := false
+	for i := range ring {
+		a, b := ring[(i+len(ring)-1)%len(ring)], ring[i]
+		if onSegment(query, a, b) {
+			return OnEdge
+		}
+		if rayIntersectsSegment(query, a, b) {
+			in = !in
+		}
+	}
+	if in {
+		return Inside
+	}
+	return Outside
+}
+
+// onSegment returns true when p is on the segment a-b, in either direction
+func onSegment(p, a, b LatLng) bool {
+	if lessLatLng(b, a) {
+		a, b = b, a
+	}
+	if (p.Lng < a.Lng && p.Lng < b.Lng) || (p.Lng > a.Lng && p.Lng > b.Lng) ||
+		(p.Lat < a.Lat && p.Lat < b.Lat) || (p.Lat > a.Lat && p.Lat > b.Lat) {
+		return false
+	}
+	return (b.Lng-a.Lng)*(p.Lat-a.Lat) == (b.Lat-a.Lat)*(p.Lng-a.Lng)
 }
 
+// ToByteSlice encodes the Polygon with Float32 precision, see Encode.
 func (p Polygon) ToByteSlice() []byte {
-	return toByteSlice(p.v)
+	return p.Encode(Float32)
 }
 
+// Encode encodes the Polygon with the vertices in precision prec as:
+// [4]holes [4*holes]hole start index [VertexSize*vertices]vertices
+func (p Polygon) Encode(prec Precision) []byte {
+	header := 4 + 4*len(p.holes)
+	size := prec.VertexSize()
+	b := make([]byte, header+size*len(p.v))
+	binary.LittleEndian.PutUint32(b, uint32(len(p.holes)))
+	for i, h := range p.holes {
+		binary.LittleEndian.PutUint32(b[4+4*i:], h)
+	}
+	for i, ll := range p.v {
+		prec.put(b[header+size*i:], ll)
+	}
+	return b
+}
+
+// FromByteSlice decodes a Polygon encoded with ToByteSlice, see Decode.
 func (p *Polygon) FromByteSlice(src []byte) {
-	p.v = toLatLngSlice(src)
+	p.Decode(src, Float32)
+}
+
+// Decode decodes a Polygon encoded with Encode and precision prec. The vertices
+// are decoded into the Polygon's buffers, which are reused by later calls.
+// The Polygon is empty when src is not valid. The bounding box is not updated.
+func (p *Polygon) Decode(src []byte, prec Precision) {
+	p.v, p.holes = p.v[:0], p.holes[:0]
+	size := prec.VertexSize()
+	if len(src) < 4 || size == 0 {
+		return
+	}
+	holes := int(binary.LittleEndian.Uint32(src))
+	header := 4 + 4*holes
+	if holes < 0 || len(src) < header {
+		return
+	}
+	for i := 0; i < holes; i++ {
+		p.holes = append(p.holes, binary.LittleEndian.Uint32(src[4+4*i:]))
+	}
+	n := (len(src) - header) / size
+	var prev uint32
+	for _, h := range p.holes {
+		if h < prev || int(h) > n {
+			p.holes = p.holes[:0]
+			return
+		}
+		prev = h
+	}
+	for i := 0; i < n; i++ {
+		p.v = append(p.v, prec.get(src[header+size*i:]))
+	}
 }
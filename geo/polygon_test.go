@@ -0,0 +1,66 @@
+// Copyright 2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestContainsLatLngWithBoundary(t *testing.T) {
+	p := polygon(ring(0, 0, 0, 10, 10, 10, 10, 0), ring(4, 4, 4, 6, 6, 6, 6, 4))
+	tests := []struct {
+		ll   LatLng
+		want Location
+	}{
+		{LatLng{2, 2}, Inside},
+		{LatLng{8, 5}, Inside},
+		{LatLng{5, 5}, Outside}, // within the hole
+		{LatLng{-1, 5}, Outside},
+		{LatLng{5, 11}, Outside},
+		{LatLng{10, 11}, Outside}, // on the line of an edge, past its vertex
+		{LatLng{0, 5}, OnEdge},
+		{LatLng{5, 0}, OnEdge},
+		{LatLng{10, 3.3}, OnEdge},
+		{LatLng{7.5, 10}, OnEdge},
+		{LatLng{0, 0}, OnEdge}, // vertices
+		{LatLng{10, 10}, OnEdge},
+		{LatLng{4, 5}, OnEdge}, // hole edges and vertices
+		{LatLng{5, 6}, OnEdge},
+		{LatLng{6, 4}, OnEdge},
+	}
+	for _, tt := range tests {
+		if got := p.ContainsLatLngWithBoundary(tt.ll); got != tt.want {
+			t.Errorf("ContainsLatLngWithBoundary(%v) = %v, want %v", tt.ll, got, tt.want)
+		}
+		if got, want := p.ContainsLatLng(tt.ll), tt.want == Inside; got != want && tt.want != OnEdge {
+			t.Errorf("ContainsLatLng(%v) = %v, want %v", tt.ll, got, want)
+		}
+	}
+}
+
+// TestSharedEdge tests that points on an edge shared by two rings in opposite
+// directions are on the edge of both or within exactly one of them.
+func TestSharedEdge(t *testing.T) {
+	a, b := LatLng{0.1234567, 1.7654321}, LatLng{3.3333333, 9.87654321}
+	// the triangles on either side of the edge a-b, which they walk in opposite directions
+	left := polygon([]LatLng{a, b, {3.3333333, 1.7654321}, a})
+	right := polygon([]LatLng{b, a, {0.1234567, 9.87654321}, b})
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100000; i++ {
+		f := r.Float64()
+		ll := LatLng{a.Lat + f*(b.Lat-a.Lat), a.Lng + f*(b.Lng-a.Lng)}
+		l, r := left.ContainsLatLngWithBoundary(ll), right.ContainsLatLngWithBoundary(ll)
+		if l == OnEdge && r == OnEdge {
+			continue
+		}
+		if l == r || l == OnEdge || r == OnEdge {
+			t.Fatalf("%v is %v the left triangle and %v the right triangle", ll, l, r)
+		}
+		if left.ContainsLatLng(ll) == right.ContainsLatLng(ll) {
+			t.Fatalf("%v is within both or neither triangle", ll)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+var (
+	ErrPrecisionNotValid = errors.New("error precision is not valid")
+)
+
+// Precision is the encoding of the vertices of an encoded Polygon
+type Precision uint8
+
+const (
+	// Float32 encodes vertices as float32 degrees in 8 bytes.
+	// The precision is about 1 meter near the antimeridian.
+	Float32 Precision = iota
+
+	// Float64 encodes vertices as float64 degrees in 16 bytes.
+	Float64
+
+	// MicroDegrees encodes vertices as int32 fixed-point micro-degrees in 8 bytes.
+	// The precision is about 0.1 meter everywhere.
+	MicroDegrees
+)
+
+// String returns the name of the precision
+func (p Precision) String() string {
+	switch p {
+	case Float32:
+		return "float32"
+	case Float64:
+		return "float64"
+	case MicroDegrees:
+		return "microdegrees"
+	}
+	return "unknown"
+}
+
+// Valid returns true when p is a known Precision
+func (p Precision) Valid() bool {
+	return p <= MicroDegrees
+}
+
+// ParsePrecision returns the Precision with the name s, see Precision.String.
+func ParsePrecision(s string) (Precision, error) {
+	for p := Float32; p.Valid(); p++ {
+		if p.String() == s {
+			return p, nil
+		}
+	}
+	return 0, ErrPrecisionNotValid
+}
+
+// VertexSize returns the number of bytes of an encoded vertex
+func (p Precision) VertexSize() int {
+	switch p {
+	case Float32, MicroDegrees:
+		return 8
+	case Float64:
+		return 16
+	}
+	return 0
+}
+
+// scale returns the number of fixed-point units per degree of the Delta codec
+func (p Precision) scale() float64 {
+	switch p {
+	case Float32:
+		return 1e7
+	case Float64:
+		return 1e9
+	case MicroDegrees:
+		return 1e6
+	}
+	return 0
+}
+
+// Quantize returns ll rounded to the precision, as it is decoded after encoding
+func (p Precision) Quantize(ll LatLng) LatLng {
+	var b [16]byte
+	p.put(b[:], ll)
+	return p.get(b[:])
+}
+
+// put encodes ll into b as [lat][lng]
+func (p Precision) put(b []byte, ll LatLng) {
+	switch p {
+	case Float32:
+		binary.LittleEndian.PutUint32(b[0:4], math.Float32bits(float32(ll.Lat)))
+		binary.LittleEndian.PutUint32(b[4:8], math.Float32bits(float32(ll.Lng)))
+	case Float64:
+		binary.LittleEndian.PutUint64(b[0:8], math.Float64bits(ll.Lat))
+		binary.LittleEndian.PutUint64(b[8:16], math.Float64bits(ll.Lng))
+	case MicroDegrees:
+		binary.LittleEndian.PutUint32(b[0:4], uint32(int32(math.Round(ll.Lat*1e6))))
+		binary.LittleEndian.PutUint32(b[4:8], uint32(int32(math.Round(ll.Lng*1e6))))
+	}
+}
+
+// get decodes a LatLng encoded with put from b
+func (p Precision) get(b []byte) LatLng {
+	switch p {
+	case Float32:
+		return LatLng{
+			Lat: float64(math.Float32frombits(binary.LittleEndian.Uint32(b[0:4]))),
+			Lng: float64(math.Float32frombits(binary.LittleEndian.Uint32(b[4:8]))),
+		}
+	case Float64:
+		return LatLng{
+			Lat: math.Float64frombits(binary.LittleEndian.Uint64(b[0:8])),
+			Lng: math.Float64frombits(binary.LittleEndian.Uint64(b[8:16])),
+		}
+	case MicroDegrees:
+		return LatLng{
+			Lat: float64(int32(binary.LittleEndian.Uint32(b[0:4]))) / 1e6,
+			Lng: float64(int32(binary.LittleEndian.Uint32(b[4:8]))) / 1e6,
+		}
+	}
+	return LatLng{}
+}
+
+// float32Down returns the largest float32 that is not greater than f
+func float32Down(f float64) float32 {
+	r := float32(f)
+	if float64(r) > f {
+		r = math.Nextafter32(r, float32(math.Inf(-1)))
+	}
+	return r
+}
+
+// float32Up returns the smallest float32 that is not less than f
+func float32Up(f float64) float32 {
+	r := float32(f)
+	if float64(r) < f {
+		r = math.Nextafter32(r, float32(math.Inf(1)))
+	}
+	return r
+}
@@ -0,0 +1,530 @@
+// Copyright 2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"math"
+	"sort"
+)
+
+// SimplifyPolygons returns the polygons with their rings simplified by the
+// Douglas-Peucker algorithm, which removes the vertices that are within
+// tolerance meters of the simplified line. The vertices that are kept are
+// vertices of the polygons.
+//
+// Borders shared by the polygons stay coincident: the rings are split into
+// chains at the vertices where the set of rings that contain the vertex changes,
+// and every chain is simplified in the same direction for each ring that
+// contains it. Borders are shared when their vertices are identical. Chains
+// that cross themselves or any other chain once simplified, and the chains of a
+// ring that would no longer have the vertices of another ring on the same side,
+// ex: a hole, are simplified with a smaller tolerance. A ring that would be left
+// with less than 3 vertices is not simplified, and neither are the borders it
+// shares.
+func SimplifyPolygons(pp []Polygon, tolerance float64) []Polygon {
+	if !(tolerance > 0) {
+		return pp
+	}
+	s := simplifier{tolerance: tolerance, index: make(map[LatLng]int32), chain: make(map[chainKey]*chain), edges: make(map[[2]LatLng]struct{})}
+	for i := range pp {
+		for r := 0; r <= pp[i].Holes(); r++ {
+			s.addRing(pp[i].Ring(r))
+		}
+	}
+	s.markNodes()
+	chains := make([][]ringChain, len(s.rings))
+	for r := range s.rings {
+		chains[r] = s.chains(r)
+	}
+	// rings that would collapse keep the vertices of their chains
+	for r, rc := range chains {
+		if rc != nil && distinct(s.assemble(r, rc)) < 3 {
+			for _, c := range rc {
+				c.c.original = true
+			}
+		}
+	}
+	s.uncross(chains)
+
+	out := make([]Polygon, len(pp))
+	r := 0
+	for i := range pp {
+		for h := 0; h <= pp[i].Holes(); h++ {
+			v := s.assemble(r, chains[r])
+			if s.closed[r] && len(v) > 0 {
+				v = append(v, v[0])
+			}
+			r++
+			if h == 0 {
+				out[i] = NewPolygonFromVertices(v)
+				continue
+			}
+			out[i].AddHole()
+			for _, ll := range v {
+				out[i].AddVertex(ll)
+			}
+		}
+	}
+	return out
+}
+
+// simplifier holds the rings of the polygons that are simplified together
+type simplifier struct {
+	tolerance float64
+	rings     [][]LatLng       // rings without the closing vertex
+	closed    []bool           // ring had a closing vertex
+	index     map[LatLng]int32 // index of the vertex in shared
+	shared    []sharing
+	chain     map[chainKey]*chain
+	edges     map[[2]LatLng]struct{} // edges of the rings, see edge
+}
+
+// sharing is the set of rings that contain a vertex in increasing order
+type sharing struct {
+	rings [2]int32 // first two rings, -1 when none
+	more  []int32  // any further rings
+	node  bool     // a chain starts or ends at the vertex
+}
+
+func (a *sharing) add(ring int32) {
+	switch {
+	case a.rings[0] == ring || a.rings[1] == ring || (len(a.more) > 0 && a.more[len(a.more)-1] == ring):
+	case a.rings[0] < 0:
+		a.rings[0] = ring
+	case a.rings[1] < 0:
+		a.rings[1] = ring
+	default:
+		a.more = append(a.more, ring)
+	}
+}
+
+func (a *sharing) equal(b *sharing) bool {
+	return a.rings == b.rings && equalRings(a.more, b.more)
+}
+
+// chain is a part of a ring between two nodes, in canonical direction
+type chain struct {
+	v         []LatLng // vertices
+	keep      []LatLng // simplified vertices
+	tolerance float64  // tolerance of keep
+	original  bool     // keep all vertices
+}
+
+type chainKey struct {
+	first, second, last LatLng
+	n                   int
+}
+
+// ringChain is a chain of a ring, reversed when the ring runs against the canonical direction
+type ringChain struct {
+	c        *chain
+	reversed bool
+}
+
+func (s *simplifier) addRing(ring []LatLng) {
+	closed := len(ring) > 1 && ring[0] == ring[len(ring)-1]
+	if closed {
+		ring = ring[:len(ring)-1]
+	}
+	r := int32(len(s.rings))
+	s.rings = append(s.rings, ring)
+	s.closed = append(s.closed, closed)
+	for j, ll := range ring {
+		s.edges[edge(ring[(j+len(ring)-1)%len(ring)], ll)] = struct{}{}
+		i, ok := s.index[ll]
+		if !ok {
+			i = int32(len(s.shared))
+			s.index[ll] = i
+			s.shared = append(s.shared, sharing{rings: [2]int32{-1, -1}})
+		}
+		s.shared[i].add(r)
+	}
+}
+
+// markNodes marks the vertices where the rings that share the edges before
+// and after the vertex differ along any ring, and the vertices that are shared
+// by more rings than their edges, ex: where two rings touch.
+func (s *simplifier) markNodes() {
+	var v, prev, next, before, after []int32
+	for _, ring := range s.rings {
+		for i, ll := range ring {
+			a := &s.shared[s.index[ll]]
+			p := &s.shared[s.index[ring[(i+len(ring)-1)%len(ring)]]]
+			n := &s.shared[s.index[ring[(i+1)%len(ring)]]]
+			if a.node || (a.equal(p) && a.equal(n)) {
+				continue
+			}
+			// the rings of an edge are the rings that contain both of its vertices
+			v, prev, next = a.appendRings(v[:0]), p.appendRings(prev[:0]), n.appendRings(next[:0])
+			before, after = intersect(before[:0], prev, v), intersect(after[:0], v, next)
+			a.node = !equalRings(before, after) || !equalRings(v, before)
+		}
+	}
+}
+
+// appendRings appends the rings of a to rings
+func (a *sharing) appendRings(rings []int32) []int32 {
+	for _, r := range a.rings {
+		if r >= 0 {
+			rings = append(rings, r)
+		}
+	}
+	return append(rings, a.more...)
+}
+
+// intersect appends the rings that are in both of the sorted a and b to rings
+func intersect(rings, a, b []int32) []int32 {
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			rings = append(rings, a[i])
+			i++
+			j++
+		}
+	}
+	return rings
+}
+
+func equalRings(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// chains splits ring r into chains at its nodes. A ring without nodes starts
+// at its smallest vertex, which is the same for every ring with the same vertices.
+// Returns nil for a ring with less than 3 vertices, which is not simplified.
+func (s *simplifier) chains(r int) []ringChain {
+	ring := s.rings[r]
+	if len(ring) < 3 {
+		return nil
+	}
+	var nodes []int
+	for i, ll := range ring {
+		if s.shared[s.index[ll]].node {
+			nodes = append(nodes, i)
+		}
+	}
+	if len(nodes) == 0 {
+		min := 0
+		for i, ll := range ring {
+			if lessLatLng(ll, ring[min]) {
+				min = i
+			}
+		}
+		nodes = append(nodes, min)
+	}
+	rc := make([]ringChain, len(nodes))
+	for j, start := range nodes {
+		end := nodes[(j+1)%len(nodes)]
+		if end <= start {
+			end += len(ring)
+		}
+		v := make([]LatLng, 0, end-start+1)
+		for i := start; i <= end; i++ {
+			v = append(v, ring[i%len(ring)])
+		}
+		rc[j] = s.canonical(v)
+	}
+	return rc
+}
+
+// canonical returns the chain of the vertices v simplified in canonical direction
+func (s *simplifier) canonical(v []LatLng) ringChain {
+	n := len(v) - 1
+	reversed := lessLatLng(v[n], v[0]) || (v[0] == v[n] && n > 1 && lessLatLng(v[n-1], v[1]))
+	if reversed {
+		rv := make([]LatLng, len(v))
+		for i := range v {
+			rv[n-i] = v[i]
+		}
+		v = rv
+	}
+	key := chainKey{first: v[0], second: v[1], last: v[n], n: n}
+	c, ok := s.chain[key]
+	if !ok {
+		c = &chain{v: v, keep: douglasPeucker(v, s.tolerance), tolerance: s.tolerance}
+		s.chain[key] = c
+	}
+	return ringChain{c: c, reversed: reversed}
+}
+
+// maxUncross is the maximum number of times that the tolerance of a chain is reduced
+const maxUncross = 16
+
+// uncross simplifies the chains that cross any chain, including themselves and
+// the chains of other rings, with a quarter of their tolerance until they no
+// longer cross. So are the chains of a ring that would have a vertex of another
+// ring on its other side. Chains that still cross keep all their vertices.
+func (s *simplifier) uncross(chains [][]ringChain) {
+	var cc []*chain
+	index := make(map[*chain]int)
+	for _, rc := range chains {
+		for _, c := range rc {
+			if _, ok := index[c.c]; !ok {
+				index[c.c] = len(cc)
+				cc = append(cc, c.c)
+			}
+		}
+	}
+	crossed := make([]bool, len(cc))
+	for pass := 0; ; pass++ {
+		for i := range crossed {
+			crossed[i] = false
+		}
+		s.crossings(cc, crossed)
+		s.displaced(chains, index, crossed)
+		reduced := false
+		for i, c := range cc {
+			if !crossed[i] || c.original {
+				continue
+			}
+			reduced = true
+			if pass == maxUncross {
+				c.original = true
+				continue
+			}
+			c.tolerance /= 4
+			c.keep = douglasPeucker(c.v, c.tolerance)
+		}
+		if !reduced || pass == maxUncross {
+			return
+		}
+	}
+}
+
+// segment is a segment of the polyline of a chain with its bounding box
+type segment struct {
+	a, b     LatLng
+	min, max LatLng
+	chain    int
+}
+
+// crossings marks the chains of cc with a segment that crosses another segment
+// of any chain, unless both are edges of the rings that already cross. Segments
+// are swept in the order of their minimum latitude, so that only segments with
+// intersecting bounding boxes are compared.
+func (s *simplifier) crossings(cc []*chain, crossed []bool) {
+	var segs []segment
+	for i, c := range cc {
+		v := c.line()
+		for j := 1; j < len(v); j++ {
+			min, max := polylineBounds(v[j-1 : j+1])
+			segs = append(segs, segment{a: v[j-1], b: v[j], min: min, max: max, chain: i})
+		}
+	}
+	sort.Slice(segs, func(i, j int) bool { return segs[i].min.Lat < segs[j].min.Lat })
+	for i := range segs {
+		a := &segs[i]
+		for j := i + 1; j < len(segs) && segs[j].min.Lat <= a.max.Lat; j++ {
+			b := &segs[j]
+			if b.max.Lng < a.min.Lng || a.max.Lng < b.min.Lng || !segmentsCross(a.a, a.b, b.a, b.b) {
+				continue
+			}
+			_, aok := s.edges[edge(a.a, a.b)]
+			if _, bok := s.edges[edge(b.a, b.b)]; !aok || !bok {
+				crossed[a.chain], crossed[b.chain] = true, true
+			}
+		}
+	}
+}
+
+// displaced marks the chains of the rings that have a vertex of another ring
+// on the other side of them once simplified, ex: a hole that a simplified
+// exterior ring no longer contains without crossing it.
+func (s *simplifier) displaced(chains [][]ringChain, index map[*chain]int, crossed []bool) {
+	type bounds struct{ min, max LatLng }
+	simplified := make([][]LatLng, len(chains))
+	bb := make([]bounds, len(chains))
+	for r, rc := range chains {
+		if rc == nil {
+			continue
+		}
+		simplified[r] = s.assemble(r, rc)
+		min, max := polylineBounds(s.rings[r])
+		smin, smax := polylineBounds(simplified[r])
+		bb[r].min, bb[r].max = ExpandBounds(min, max, smin, smax)
+	}
+	for r, rc := range chains {
+		if rc == nil {
+			continue
+		}
+		for o, ring := range s.rings {
+			if o == r {
+				continue
+			}
+			ll, ok := s.vertexApart(ring, int32(r))
+			if !ok || ll.Lat < bb[r].min.Lat || ll.Lat > bb[r].max.Lat || ll.Lng < bb[r].min.Lng || ll.Lng > bb[r].max.Lng {
+				continue
+			}
+			if ringContains(s.rings[r], ll) != ringContains(simplified[r], ll) {
+				for _, c := range rc {
+					crossed[index[c.c]] = true
+				}
+				break
+			}
+		}
+	}
+}
+
+// vertexApart returns a vertex of ring that is not a vertex of ring r
+func (s *simplifier) vertexApart(ring []LatLng, r int32) (LatLng, bool) {
+	for _, ll := range ring {
+		a := &s.shared[s.index[ll]]
+		if a.rings[0] == r || a.rings[1] == r {
+			continue
+		}
+		shared := false
+		for _, m := range a.more {
+			shared = shared || m == r
+		}
+		if !shared {
+			return ll, true
+		}
+	}
+	return LatLng{}, false
+}
+
+// edge returns the segment a-b in the same order as b-a
+func edge(a, b LatLng) [2]LatLng {
+	if lessLatLng(b, a) {
+		return [2]LatLng{b, a}
+	}
+	return [2]LatLng{a, b}
+}
+
+// line returns the vertices of the chain as it is assembled
+func (c *chain) line() []LatLng {
+	if c.original {
+		return c.v
+	}
+	return c.keep
+}
+
+func polylineBounds(v []LatLng) (min, max LatLng) {
+	min, max = v[0], v[0]
+	for _, ll := range v[1:] {
+		min.Lat, min.Lng = math.Min(min.Lat, ll.Lat), math.Min(min.Lng, ll.Lng)
+		max.Lat, max.Lng = math.Max(max.Lat, ll.Lat), math.Max(max.Lng, ll.Lng)
+	}
+	return min, max
+}
+
+// segmentsCross returns true when the segments a-b and c-d intersect other than
+// at an endpoint they share.
+func segmentsCross(a, b, c, d LatLng) bool {
+	if a == c || a == d || b == c || b == d {
+		// segments that share an endpoint only cross when they overlap
+		return orientation(a, b, c) == 0 && orientation(a, b, d) == 0 &&
+			(onSegment(c, a, b) && c != a && c != b || onSegment(d, a, b) && d != a && d != b ||
+				onSegment(a, c, d) && a != c && a != d || onSegment(b, c, d) && b != c && b != d)
+	}
+	o1, o2 := orientation(a, b, c), orientation(a, b, d)
+	o3, o4 := orientation(c, d, a), orientation(c, d, b)
+	if o1 != o2 && o3 != o4 && o1 != 0 && o2 != 0 && o3 != 0 && o4 != 0 {
+		return true
+	}
+	return (o1 == 0 && onSegment(c, a, b)) || (o2 == 0 && onSegment(d, a, b)) ||
+		(o3 == 0 && onSegment(a, c, d)) || (o4 == 0 && onSegment(b, c, d))
+}
+
+// orientation returns the sign of the turn from a-b to a-c
+func orientation(a, b, c LatLng) int {
+	switch v := (b.Lng-a.Lng)*(c.Lat-a.Lat) - (b.Lat-a.Lat)*(c.Lng-a.Lng); {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+// assemble returns the vertices of ring r from its chains rc, without the closing vertex
+func (s *simplifier) assemble(r int, rc []ringChain) []LatLng {
+	if rc == nil {
+		return append([]LatLng(nil), s.rings[r]...)
+	}
+	var v []LatLng
+	for _, c := range rc {
+		cv := c.c.keep
+		if c.c.original {
+			cv = c.c.v
+		}
+		// the last vertex is the first of the next chain
+		for i := 0; i < len(cv)-1; i++ {
+			if c.reversed {
+				v = append(v, cv[len(cv)-1-i])
+			} else {
+				v = append(v, cv[i])
+			}
+		}
+	}
+	return v
+}
+
+// douglasPeucker returns the vertices of v that are kept by the Douglas-Peucker
+// algorithm with a tolerance in meters. The first and last vertices are kept.
+func douglasPeucker(v []LatLng, tolerance float64) []LatLng {
+	if len(v) < 3 {
+		return v
+	}
+	keep := make([]bool, len(v))
+	keep[0], keep[len(v)-1] = true, true
+	stack := [][2]int{{0, len(v) - 1}}
+	for len(stack) > 0 {
+		a, b := stack[len(stack)-1][0], stack[len(stack)-1][1]
+		stack = stack[:len(stack)-1]
+		max, index := 0.0, -1
+		for i := a + 1; i < b; i++ {
+			if d := segmentDistance(v[i], v[a], v[b]); d > max {
+				max, index = d, i
+			}
+		}
+		if index >= 0 && max > tolerance {
+			keep[index] = true
+			stack = append(stack, [2]int{a, index}, [2]int{index, b})
+		}
+	}
+	var out []LatLng
+	for i, k := range keep {
+		if k {
+			out = append(out, v[i])
+		}
+	}
+	return out
+}
+
+// segmentDistance returns the distance in meters from ll to the segment a-b,
+// see DistanceTo.
+func segmentDistance(ll, a, b LatLng) float64 {
+	kx := math.Cos(radians(ll.Lat)) * MetersPerDegree
+	ax, ay := lngDelta(ll.Lng, a.Lng)*kx, (a.Lat-ll.Lat)*MetersPerDegree
+	bx, by := lngDelta(ll.Lng, b.Lng)*kx, (b.Lat-ll.Lat)*MetersPerDegree
+	return distanceToSegment(ax, ay, bx, by)
+}
+
+// lessLatLng orders LatLng by latitude and then longitude
+func lessLatLng(a, b LatLng) bool {
+	return a.Lat < b.Lat || (a.Lat == b.Lat && a.Lng < b.Lng)
+}
+
+// distinct returns the number of distinct vertices of the ring v
+func distinct(v []LatLng) int {
+	seen := make(map[LatLng]struct{}, len(v))
+	for _, ll := range v {
+		seen[ll] = struct{}{}
+	}
+	return len(seen)
+}
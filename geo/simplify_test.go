@@ -0,0 +1,100 @@
+// Copyright 2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package geo
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// wiggly returns the vertices of a border from south to north at about lng
+// with a wiggle of about 500 meters every 0.01°.
+func wiggly(lng float64) []LatLng {
+	var v []LatLng
+	for i := 0; i <= 100; i++ {
+		lat := float64(i) / 100
+		v = append(v, LatLng{lat, lng + 0.005*math.Sin(float64(i)*1.3)})
+	}
+	return v
+}
+
+func TestSimplifySharedBorder(t *testing.T) {
+	border := wiggly(0.5)
+	west := append([]LatLng{{1, 0}, {0, 0}}, border...)
+	west = append(west, LatLng{1, 0})
+	east := []LatLng{{0, 1}, {1, 1}}
+	for i := len(border) - 1; i >= 0; i-- {
+		east = append(east, border[i])
+	}
+	east = append(east, LatLng{0, 1})
+	pp := SimplifyPolygons([]Polygon{polygon(west), polygon(east)}, 1000)
+	if len(pp) != 2 || pp[0].Length() >= len(west) || pp[1].Length() >= len(east) {
+		t.Fatalf("simplified to %d and %d vertices from %d and %d", pp[0].Length(), pp[1].Length(), len(west), len(east))
+	}
+	// the border vertices of both polygons are the same
+	kept := make(map[LatLng]int)
+	for _, p := range pp {
+		vertices := make(map[LatLng]bool)
+		for _, ll := range p.Ring(0) {
+			if ll.Lng > 0 && ll.Lng < 1 && !vertices[ll] {
+				vertices[ll] = true
+				kept[ll]++
+			}
+		}
+	}
+	for ll, n := range kept {
+		if n != 2 {
+			t.Fatalf("border vertex %v is a vertex of %d polygons", ll, n)
+		}
+	}
+	// no gaps or overlaps
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		ll := LatLng{r.Float64(), r.Float64()}
+		w, e := pp[0].ContainsLatLngWithBoundary(ll), pp[1].ContainsLatLngWithBoundary(ll)
+		if !(w == OnEdge && e == OnEdge) && (w == e || w == OnEdge || e == OnEdge) {
+			t.Fatalf("%v is %v the west and %v the east polygon", ll, w, e)
+		}
+	}
+}
+
+// TestSimplifyHole tests that a hole stays within its exterior ring when the
+// notch of the exterior ring around it is simplified away.
+func TestSimplifyHole(t *testing.T) {
+	p := polygon(
+		ring(-0.001, 0, 0.0015, 0, 0.0015, 0.05, 0, 0.05, 0, 0.053, 0.0015, 0.053, 0.0015, 0.1,
+			1, 0.1, 1.0001, 0.075, 1, 0.05, 1.0001, 0.025, 1, 0),
+		ring(0.0002, 0.0512, 0.0002, 0.0518, 0.0006, 0.0518, 0.0006, 0.0512),
+	)
+	s := SimplifyPolygons([]Polygon{p}, 200)[0]
+	if s.Length() >= p.Length() || s.Holes() != 1 {
+		t.Fatalf("simplified to %d vertices and %d holes", s.Length(), s.Holes())
+	}
+	exterior := NewPolygonFromVertices(s.Ring(0))
+	for _, ll := range s.Ring(1) {
+		if exterior.ContainsLatLngWithBoundary(ll) != Inside {
+			t.Fatalf("hole vertex %v is %v the exterior ring %v", ll, exterior.ContainsLatLngWithBoundary(ll), s.Ring(0))
+		}
+	}
+}
+
+func TestSimplifyCollapse(t *testing.T) {
+	// 100 meters across with a tolerance of 1km
+	tri := ring(0, 0, 0, 0.001, 0.0005, 0.0005)
+	sq := ring(1, 1, 1, 1.001, 1.001, 1.001, 1.001, 1)
+	pp := SimplifyPolygons([]Polygon{polygon(tri), polygon(sq)}, 1000)
+	for i, want := range [][]LatLng{tri, sq} {
+		got := pp[i].Ring(0)
+		if len(got) != len(want) {
+			t.Fatalf("polygon %d simplified to %v, want %v", i, got, want)
+		}
+		for j := range want {
+			if got[j] != want[j] {
+				t.Fatalf("polygon %d simplified to %v, want %v", i, got, want)
+			}
+		}
+	}
+}
@@ -1,16 +1,21 @@
 package timezoneLookup
 
 import (
-	"encoding/json"
+	"bufio"
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/evanoberholster/timezoneLookup/v2/geo"
+	"github.com/klauspost/compress/gzip"
 	"github.com/klauspost/compress/zip"
 )
 
@@ -24,17 +29,82 @@ func Verbose(v bool) {
 }
 
 const (
-	DefaultURL = "https://github.com/evansiroky/timezone-boundary-builder/releases/download/2020d/timezones-with-oceans.geojson.zip"
+	DefaultRelease = "2020d"
+	DefaultURL     = "https://github.com/evansiroky/timezone-boundary-builder/releases/download/" + DefaultRelease + "/timezones-with-oceans.geojson.zip"
 )
 
+// ReleaseFromURL returns the timezone-boundary-builder release from a release download url.
+// Returns an empty string when the url is not a release download url.
+func ReleaseFromURL(url string) string {
+	const prefix = "/releases/download/"
+	i := strings.Index(url, prefix)
+	if i < 0 {
+		return ""
+	}
+	release := url[i+len(prefix):]
+	if j := strings.IndexByte(release, '/'); j >= 0 {
+		return release[:j]
+	}
+	return ""
+}
+
+// Importer imports GeoJSON timezone boundaries. The zero value stops at the
+// first feature that can not be decoded and returns a *DecodeError.
+// Polygons that cross the antimeridian are split at ±180°.
+type Importer struct {
+	// Lenient skips the features that can not be decoded and collects their
+	// errors in Warnings instead. Malformed JSON still stops the import.
+	Lenient bool
+
+	// Warnings are the features that were skipped in lenient mode
+	Warnings []*DecodeError
+}
+
 // ImportZipFile imports a url and saves it with the following filename. The iter function is run on the zip file.
 func ImportZipFile(cache string, url string, iter func(tz Timezone) error) (err error) {
+	return ImportZipFileContext(context.Background(), cache, url, iter)
+}
+
+// ImportZipFileContext is ImportZipFile with a context. The download and the decoding
+// of features stop when ctx is done and ctx.Err() is returned.
+func ImportZipFileContext(ctx context.Context, cache string, url string, iter func(tz Timezone) error) (err error) {
+	return new(Importer).ImportZipFile(ctx, cache, url, iter)
+}
+
+// ImportFile imports a local GeoJSON file without downloading it. The format is
+// detected from the content: a zip file of GeoJSON files, a gzipped GeoJSON file
+// or a plain GeoJSON file. When filename is a directory, every .json, .geojson,
+// .gz and .zip file in it and its subdirectories is imported in lexical order.
+func ImportFile(filename string, iter func(tz Timezone) error) error {
+	return ImportFileContext(context.Background(), filename, iter)
+}
+
+// ImportFileContext is ImportFile with a context. The decoding of features stops
+// when ctx is done and ctx.Err() is returned.
+func ImportFileContext(ctx context.Context, filename string, iter func(tz Timezone) error) error {
+	return new(Importer).ImportFile(ctx, filename, iter)
+}
+
+// ImportGeoJSON imports a GeoJSON FeatureCollection or Feature from r.
+// The iter function is run on every feature.
+func ImportGeoJSON(r io.Reader, iter func(tz Timezone) error) error {
+	return ImportGeoJSONContext(context.Background(), r, iter)
+}
+
+// ImportGeoJSONContext is ImportGeoJSON with a context. The decoding of features
+// stops when ctx is done and ctx.Err() is returned.
+func ImportGeoJSONContext(ctx context.Context, r io.Reader, iter func(tz Timezone) error) error {
+	return new(Importer).ImportGeoJSON(ctx, r, iter)
+}
+
+// ImportZipFile is ImportZipFileContext with the options of the Importer
+func (im *Importer) ImportZipFile(ctx context.Context, cache string, url string, iter func(tz Timezone) error) (err error) {
 	start := time.Now()
 	if _, err := os.Stat(cache); errors.Is(err, os.ErrNotExist) {
 		if verbose {
 			fmt.Println("Caching url:", url, "to:", cache)
 		}
-		if err = fetchAndCacheFile(cache, url); err != nil {
+		if err = fetchAndCacheFile(ctx, cache, url); err != nil {
 			return err
 		}
 		if verbose {
@@ -45,19 +115,12 @@ func ImportZipFile(cache string, url string, iter func(tz Timezone) error) (err
 	if verbose {
 		fmt.Println("Loading cache:", cache)
 	}
-	if !strings.EqualFold(cache[len(cache)-4:], ".zip") {
+	if !strings.EqualFold(filepath.Ext(cache), ".zip") {
 		return errors.New("error not a zip file")
 	}
-	var zr *zip.ReadCloser
-	if zr, err = zip.OpenReader(cache); err != nil {
+	if err = im.importZip(ctx, cache, iter); err != nil {
 		return err
 	}
-	defer zr.Close()
-	for _, v := range zr.File {
-		if strings.EqualFold(".json", v.Name[len(v.Name)-5:]) {
-			decodeJSON(v, iter)
-		}
-	}
 	if verbose {
 		fmt.Println("Time to process timezones:", time.Since(start))
 	}
@@ -65,110 +128,128 @@ func ImportZipFile(cache string, url string, iter func(tz Timezone) error) (err
 	return nil
 }
 
-func fetchAndCacheFile(filename string, url string) (err error) {
-	resp, err := http.Get(url)
+// ImportFile is ImportFileContext with the options of the Importer
+func (im *Importer) ImportFile(ctx context.Context, filename string, iter func(tz Timezone) error) error {
+	fi, err := os.Stat(filename)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
+	if !fi.IsDir() {
+		return im.importFile(ctx, filename, iter)
+	}
+	return filepath.WalkDir(filename, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return ctx.Err()
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".json", ".geojson", ".gz", ".zip":
+			if verbose {
+				fmt.Println("Importing:", path)
+			}
+			return im.importFile(ctx, path, iter)
+		}
+		return nil
+	})
+}
 
-	f, err := os.OpenFile(filename, os.O_CREATE|os.O_RDWR, 0666)
+// ImportGeoJSON is ImportGeoJSONContext with the options of the Importer
+func (im *Importer) ImportGeoJSON(ctx context.Context, r io.Reader, iter func(tz Timezone) error) error {
+	return im.decodeJSON(ctx, "", r, iter)
+}
+
+var (
+	zipMagic  = []byte("PK\x03\x04")
+	gzipMagic = []byte{0x1f, 0x8b}
+)
+
+// importFile imports a zip, gzip or plain GeoJSON file detected by its magic number
+func (im *Importer) importFile(ctx context.Context, filename string, iter func(tz Timezone) error) error {
+	f, err := os.Open(filename)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
+	br := bufio.NewReader(f)
+	magic, _ := br.Peek(len(zipMagic))
+	switch {
+	case bytes.HasPrefix(magic, zipMagic):
+		return im.importZip(ctx, filename, iter)
+	case bytes.HasPrefix(magic, gzipMagic):
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		return im.decodeJSON(ctx, filename, zr, iter)
+	}
+	return im.decodeJSON(ctx, filename, br, iter)
+}
 
-	n, err := io.Copy(f, resp.Body)
-	if err != nil {
+// importZip imports the .json and .geojson files of a zip file
+func (im *Importer) importZip(ctx context.Context, filename string, iter func(tz Timezone) error) (err error) {
+	var zr *zip.ReadCloser
+	if zr, err = zip.OpenReader(filename); err != nil {
 		return err
 	}
-	if n != resp.ContentLength {
-		fmt.Println(n, resp.ContentLength)
+	defer zr.Close()
+	for _, v := range zr.File {
+		switch strings.ToLower(filepath.Ext(v.Name)) {
+		case ".json", ".geojson":
+			if err = im.decodeZipFile(ctx, filename, v, iter); err != nil {
+				return err
+			}
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
 	}
-
-	return
+	return nil
 }
 
-func decodeJSON(f *zip.File, iter func(tz Timezone) error) (err error) {
+func (im *Importer) decodeZipFile(ctx context.Context, filename string, f *zip.File, iter func(tz Timezone) error) (err error) {
 	var rc io.ReadCloser
 	if rc, err = f.Open(); err != nil {
 		return err
 	}
 	defer rc.Close()
-
-	dec := json.NewDecoder(rc)
-
-	var token json.Token
-	for dec.More() {
-		if token, err = dec.Token(); err != nil {
-			break
-		}
-		if t, ok := token.(string); ok && t == "features" {
-			if token, err = dec.Token(); err == nil && token.(json.Delim) == '[' {
-				return decodeFeatures(dec, iter) // decode features
-			}
-		}
-	}
-	return errors.New("error no features found")
+	return im.decodeJSON(ctx, filename+"/"+f.Name, rc, iter)
 }
 
-func decodeFeatures(dec *json.Decoder, fn func(tz Timezone) error) error {
-	var f GeoJSONFeature
-	var err error
-
-	for dec.More() {
-		if err = dec.Decode(&f); err != nil {
-			return err
-		}
-		var pp []geo.Polygon
-		switch f.Geometry.Item {
-		case "Polygon":
-			pp = decodePolygons(f.Geometry.Coordinates)
-		case "MultiPolygon":
-			pp = decodeMultiPolygons(f.Geometry.Coordinates)
-		}
-		if err = fn(Timezone{Name: f.Properties.Tzid, Polygons: pp}); err != nil {
-			return err
-		}
+func fetchAndCacheFile(ctx context.Context, filename string, url string) (err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error downloading %s: %s", url, resp.Status)
 	}
 
-	return nil
-}
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0666)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
 
-// decodePolygons
-// GeoJSON Spec https://geojson.org/geojson-spec.html
-// Coordinates: [Longitude, Latitude]
-func decodePolygons(polygons []interface{}) []geo.Polygon {
-	var pp []geo.Polygon
-	for _, points := range polygons {
-		p := geo.NewPolygon()
-		for _, i := range points.([]interface{}) {
-			if latlng, ok := i.([]interface{}); ok {
-				p.AddVertex(geo.NewLatLng(latlng[1].(float64), latlng[0].(float64)))
-			}
-		}
-		pp = append(pp, p)
-	}
-	return pp
-}
-
-// decodeMultiPolygons
-// GeoJSON Spec https://geojson.org/geojson-spec.html
-// Coordinates: [Longitude, Latitude]
-func decodeMultiPolygons(polygons []interface{}) []geo.Polygon {
-	var pp []geo.Polygon
-	for _, v := range polygons {
-		p := geo.NewPolygon()
-		for _, points := range v.([]interface{}) { // 2
-			for _, i := range points.([]interface{}) {
-				if latlng, ok := i.([]interface{}); ok {
-					p.AddVertex(geo.NewLatLng(latlng[1].(float64), latlng[0].(float64)))
-				}
-			}
-		}
-		pp = append(pp, p)
+	n, err := io.Copy(f, resp.Body)
+	if err != nil {
+		// remove the partial download so that it is not used as the cache
+		f.Close()
+		os.Remove(filename)
+		return err
 	}
-	return pp
+	if n != resp.ContentLength {
+		fmt.Println(n, resp.ContentLength)
+	}
+
+	return
 }
 
 // Timezone
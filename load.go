@@ -0,0 +1,191 @@
+package timezoneLookup
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+
+	mmapgo "github.com/edsrzf/mmap-go"
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+)
+
+// Load loads the timezone data from f as a memory mapped file.
+// f can be closed once Load returns.
+func (tzc *Timezonecache) Load(f *os.File) (err error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if fi.Size() < headerSize {
+		return ErrTruncated
+	}
+	data, err := mmap(f, 0, fi.Size())
+	if err != nil {
+		return err
+	}
+	if err = tzc.load(data, true); err != nil {
+		munmap(data)
+		return err
+	}
+	return nil
+}
+
+// LoadBytes loads the timezone data from b. b is referenced directly
+// and must not be modified. ex: timezone data embedded with //go:embed
+func (tzc *Timezonecache) LoadBytes(b []byte) error {
+	return tzc.load(b, false)
+}
+
+// LoadReaderAt loads size bytes of timezone data from r. An *os.File is
+// memory mapped, any other io.ReaderAt is read into memory.
+func (tzc *Timezonecache) LoadReaderAt(r io.ReaderAt, size int64) error {
+	if f, ok := r.(*os.File); ok {
+		return tzc.Load(f)
+	}
+	if size < headerSize {
+		return ErrTruncated
+	}
+	b := make([]byte, size)
+	n, err := r.ReadAt(b, 0)
+	if int64(n) < size {
+		if err == nil || errors.Is(err, io.EOF) {
+			err = ErrTruncated
+		}
+		return err
+	}
+	return tzc.load(b, false)
+}
+
+// LoadFS loads the timezone data from the file name in fsys. ex: embed.FS or os.DirFS.
+// An *os.File is memory mapped, other files are read into memory.
+func (tzc *Timezonecache) LoadFS(fsys fs.FS, name string) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if osf, ok := f.(*os.File); ok {
+		return tzc.Load(osf)
+	}
+	if ra, ok := f.(io.ReaderAt); ok {
+		fi, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		return tzc.LoadReaderAt(ra, fi.Size())
+	}
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	return tzc.load(b, false)
+}
+
+// load decodes the header, items and packed RTree of the timezone data in b and
+// replaces the timezone data of tzc. Previously loaded data is released.
+func (tzc *Timezonecache) load(b []byte, mapped bool) (err error) {
+	var t Timezonecache
+	if err = t.decode(b); err != nil {
+		return err
+	}
+	tzc.mu.Lock()
+	defer tzc.mu.Unlock()
+	if tzc.mapped {
+		munmap(tzc.data)
+	}
+	tzc.data, tzc.mapped = b, mapped
+	tzc.arr, tzc.zone, tzc.zones, tzc.zoneIDs = t.arr, t.zone, t.zones, t.zoneIDs
+	tzc.rt, tzc.tree = geo.RTree{}, t.tree
+	tzc.dataOffset, tzc.dataLength, tzc.treeLength = t.dataOffset, t.dataLength, t.treeLength
+	tzc.bufOffset, tzc.dataSize, tzc.precision, tzc.codec = t.bufOffset, t.dataSize, t.precision, t.codec
+	tzc.release, tzc.created, tzc.checksum = t.release, t.created, t.checksum
+	tzc.state = stateOpen
+	return nil
+}
+
+// decode decodes the header, items and packed RTree of the timezone data in b.
+func (tzc *Timezonecache) decode(b []byte) (err error) {
+	release, polygons, names, err := tzc.decodeHeader(b)
+	if err != nil {
+		return err
+	}
+	offset := headerSize + release
+	if len(b) < offset {
+		return ErrTruncated
+	}
+	tzc.release = string(b[headerSize:offset])
+	n, err := tzc.decodeZones(b[offset:], names)
+	if err != nil {
+		return err
+	}
+	offset += n
+	if err = tzc.decodeItems(b[offset:], polygons); err != nil {
+		return err
+	}
+	offset += itemSize * polygons
+	if tzc.dataOffset != uint32(offset) {
+		return fmt.Errorf("%w: data offset %d does not match header length %d", ErrCorrupt, tzc.dataOffset, offset)
+	}
+	tzc.bufOffset = int64(offset)
+	size := tzc.bufOffset + int64(tzc.dataLength) + int64(tzc.treeLength)
+	if int64(len(b)) < size {
+		return ErrTruncated
+	}
+	if crc32.ChecksumIEEE(b[tzc.bufOffset:size]) != tzc.checksum {
+		return ErrChecksumMismatch
+	}
+	if tzc.tree, err = geo.NewPackedRTree(b[tzc.bufOffset+int64(tzc.dataLength) : size]); err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	// the checksum does not cover the items
+	if tzc.tree.Len() != polygons {
+		return fmt.Errorf("%w: rtree has %d items for %d polygons", ErrCorrupt, tzc.tree.Len(), polygons)
+	}
+	tzc.tree.Scan(func(_, _ geo.LatLng, id uint32) bool {
+		if id >= uint32(polygons) {
+			err = fmt.Errorf("%w: rtree item has an invalid polygon id %d", ErrCorrupt, id)
+		}
+		return err == nil
+	})
+	return err
+}
+
+// Close releases the timezone data once the searches in progress have finished.
+// Memory mapped data is unmapped. Searches after Close return ErrClosed and
+// Close returns ErrClosed when the Timezonecache is already closed.
+func (tzc *Timezonecache) Close() (err error) {
+	tzc.mu.Lock()
+	defer tzc.mu.Unlock()
+	if tzc.state == stateClosed {
+		return ErrClosed
+	}
+	if tzc.mapped {
+		err = munmap(tzc.data)
+	}
+	tzc.data, tzc.mapped = nil, false
+	tzc.arr, tzc.zone, tzc.zones, tzc.zoneIDs = nil, nil, nil, nil
+	tzc.dataSize = 0
+	tzc.rt, tzc.tree = geo.RTree{}, geo.PackedRTree{}
+	tzc.state = stateClosed
+	return err
+}
+
+func mmap(f *os.File, offset, length int64) (mmapgo.MMap, error) {
+	if f == nil {
+		return nil, errors.New("error file not open")
+	}
+
+	return mmapgo.MapRegion(f, int(length), mmapgo.RDONLY, 0, offset)
+}
+
+func munmap(data mmapgo.MMap) (err error) {
+	if data != nil {
+		err = data.Unmap()
+		data = nil
+		return
+	}
+	return errors.New("error munmap data is nil")
+}
@@ -0,0 +1,219 @@
+package timezoneLookup
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+)
+
+// readFile returns the timezone data of tzc saved to a file
+func readFile(t *testing.T, tzc *Timezonecache) []byte {
+	t.Helper()
+	b, err := os.ReadFile(saveFile(t, tzc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// updateChecksum updates the checksum in the header of the timezone data b
+func updateChecksum(b []byte) {
+	offset := endian.Uint32(b[24:28])
+	size := offset + endian.Uint32(b[28:32]) + endian.Uint32(b[40:44])
+	endian.PutUint32(b[20:24], crc32.ChecksumIEEE(b[offset:size]))
+}
+
+func TestLoadItemsCorrupt(t *testing.T) {
+	one := new(Timezonecache)
+	one.AddTimezone(Timezone{Name: "A", Polygons: []geo.Polygon{square(0, 0, 10)}})
+	b := readFile(t, one)
+
+	// the item of the only polygon removed, which the checksum does not cover
+	offset := endian.Uint32(b[24:28])
+	noItems := append(append([]byte(nil), b[:offset-itemSize]...), b[offset:]...)
+	endian.PutUint32(noItems[32:36], 0)
+	endian.PutUint32(noItems[24:28], offset-itemSize)
+
+	// the polygon id of the first rtree item after the last polygon
+	badID := readFile(t, newTestCache())
+	tree := endian.Uint32(badID[24:28]) + endian.Uint32(badID[28:32])
+	levels := uint32(endian.Uint16(badID[tree+10 : tree+12]))
+	endian.PutUint32(badID[tree+12+4*levels+16:], 3)
+	updateChecksum(badID)
+
+	for name, b := range map[string][]byte{"no items": noItems, "polygon id": badID} {
+		tzc := new(Timezonecache)
+		if err := tzc.LoadBytes(b); !errors.Is(err, ErrCorrupt) {
+			t.Fatalf("%s: LoadBytes = %v, want %v", name, err, ErrCorrupt)
+		}
+	}
+}
+
+func TestBufOutOfRange(t *testing.T) {
+	tzc := newTestCache()
+	for _, id := range []uint{3, 4, 1 << 20} {
+		if b := tzc.buf(id); b != nil {
+			t.Fatalf("buf(%d) = %v", id, b)
+		}
+	}
+	if b := new(Timezonecache).buf(0); b != nil {
+		t.Fatalf("buf(0) of an empty Timezonecache = %v", b)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	b := readFile(t, newTestCache())
+	dataOffset := endian.Uint32(b[24:28])
+	tests := []struct {
+		name   string
+		modify func(b []byte) []byte
+		err    error
+	}{
+		{"magic", func(b []byte) []byte { b[0] ^= 0xff; return b }, ErrInvalidMagic},
+		{"magic of a short file", func(b []byte) []byte { b[7] ^= 0xff; return b[:10] }, ErrInvalidMagic},
+		{"version", func(b []byte) []byte { endian.PutUint16(b[8:10], formatVersion+1); return b }, ErrUnsupportedVersion},
+		{"version 0", func(b []byte) []byte { endian.PutUint16(b[8:10], 0); return b }, ErrUnsupportedVersion},
+		{"checksum", func(b []byte) []byte { b[20] ^= 0xff; return b }, ErrChecksumMismatch},
+		{"polygon data", func(b []byte) []byte { b[dataOffset] ^= 0xff; return b }, ErrChecksumMismatch},
+		{"rtree", func(b []byte) []byte { b[len(b)-1] ^= 0xff; return b }, ErrChecksumMismatch},
+		{"precision", func(b []byte) []byte { endian.PutUint16(b[44:46], 9); return b }, ErrCorrupt},
+		{"codec", func(b []byte) []byte { endian.PutUint16(b[46:48], 9); return b }, ErrCorrupt},
+	}
+	for n := 0; n < len(b); n++ {
+		n := n
+		tests = append(tests, struct {
+			name   string
+			modify func(b []byte) []byte
+			err    error
+		}{fmt.Sprintf("truncated to %d bytes", n), func(b []byte) []byte { return b[:n] }, ErrTruncated})
+	}
+	for _, tt := range tests {
+		tzc := new(Timezonecache)
+		if err := tzc.LoadBytes(tt.modify(append([]byte(nil), b...))); !errors.Is(err, tt.err) {
+			t.Errorf("%s: LoadBytes = %v, want %v", tt.name, err, tt.err)
+		}
+	}
+	// memory mapped
+	for _, n := range []int{0, headerSize - 1, headerSize, int(dataOffset), len(b) - 1} {
+		filename := filepath.Join(t.TempDir(), "timezone.data")
+		if err := os.WriteFile(filename, b[:n], 0644); err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.Open(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = new(Timezonecache).Load(f)
+		f.Close()
+		if !errors.Is(err, ErrTruncated) {
+			t.Errorf("Load of %d bytes = %v, want %v", n, err, ErrTruncated)
+		}
+	}
+	// a file that is not truncated loads
+	if err := new(Timezonecache).LoadBytes(b); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readOnlyFS is a fs.FS with files that do not implement io.ReaderAt
+type readOnlyFS struct {
+	fs.FS
+}
+
+func (fsys readOnlyFS) Open(name string) (fs.File, error) {
+	f, err := fsys.FS.Open(name)
+	return readOnlyFile{f}, err
+}
+
+type readOnlyFile struct {
+	fs.File
+}
+
+func TestLoadFS(t *testing.T) {
+	filename := saveFile(t, newTestCache())
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapFS := fstest.MapFS{"timezone.data": &fstest.MapFile{Data: b}}
+	for name, fsys := range map[string]fs.FS{
+		"MapFS":    mapFS,
+		"readOnly": readOnlyFS{mapFS},
+		"DirFS":    os.DirFS(filepath.Dir(filename)),
+	} {
+		tzc := new(Timezonecache)
+		if err := tzc.LoadFS(fsys, "timezone.data"); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		expectSearch(t, tzc, 5, 5, "A")
+		expectSearch(t, tzc, 22, 22, "C")
+		if mapped := tzc.mapped; mapped != (name == "DirFS") {
+			t.Fatalf("%s: memory mapped %v", name, mapped)
+		}
+		if err := tzc.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := new(Timezonecache).LoadFS(fsys, "missing.data"); !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("%s: LoadFS of a missing file = %v", name, err)
+		}
+	}
+	truncated := fstest.MapFS{"timezone.data": &fstest.MapFile{Data: b[:len(b)-1]}}
+	for name, fsys := range map[string]fs.FS{"MapFS": truncated, "readOnly": readOnlyFS{truncated}} {
+		if err := new(Timezonecache).LoadFS(fsys, "timezone.data"); !errors.Is(err, ErrTruncated) {
+			t.Fatalf("%s: LoadFS of truncated data = %v, want %v", name, err, ErrTruncated)
+		}
+	}
+}
+
+// errReaderAt is an io.ReaderAt that fails with errRead after n bytes
+type errReaderAt struct {
+	r io.ReaderAt
+	n int64
+}
+
+var errRead = errors.New("error read")
+
+func (r errReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off+int64(len(p)) <= r.n {
+		return r.r.ReadAt(p, off)
+	}
+	n, _ := r.r.ReadAt(p[:r.n-off], off)
+	return n, errRead
+}
+
+func TestLoadReaderAt(t *testing.T) {
+	b := readFile(t, newTestCache())
+	tzc := new(Timezonecache)
+	if err := tzc.LoadReaderAt(bytes.NewReader(b), int64(len(b))); err != nil {
+		t.Fatal(err)
+	}
+	expectSearch(t, tzc, 5, 15, "B")
+	if tzc.mapped {
+		t.Fatal("an io.ReaderAt was memory mapped")
+	}
+	tests := []struct {
+		name string
+		r    io.ReaderAt
+		size int64
+		err  error
+	}{
+		{"short read", bytes.NewReader(b[:len(b)/2]), int64(len(b)), ErrTruncated},
+		{"size", bytes.NewReader(b), int64(len(b)) - 1, ErrTruncated},
+		{"header", bytes.NewReader(b), headerSize - 1, ErrTruncated},
+		{"read error", errReaderAt{bytes.NewReader(b), 100}, int64(len(b)), errRead},
+	}
+	for _, tt := range tests {
+		if err := new(Timezonecache).LoadReaderAt(tt.r, tt.size); !errors.Is(err, tt.err) {
+			t.Fatalf("%s: LoadReaderAt = %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
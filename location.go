@@ -0,0 +1,89 @@
+package timezoneLookup
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNoTimezone = errors.New("error no timezone found for coordinates")
+)
+
+// TimeResult is a timezone lookup result with the local time of the timezone
+type TimeResult struct {
+	Result
+	Location     *time.Location
+	Time         time.Time     // Time in Location
+	Abbreviation string        // Abbreviated zone name. ex: "PST"
+	Offset       time.Duration // Offset from UTC at Time
+	DST          bool          // True when daylight saving time is in effect at Time
+}
+
+// Location returns the *time.Location of the timezone at the given latitude and longitude.
+// Returns ErrNoTimezone when no timezone is found.
+//
+// Locations are loaded with time.LoadLocation and cached per timezone. Import
+// "time/tzdata" when the system's timezone database is not available.
+func (tzc *Timezonecache) Location(lat, lng float64) (*time.Location, error) {
+	res, err := tzc.Search(lat, lng)
+	if err != nil {
+		return nil, err
+	}
+	return tzc.loadLocation(res.Name)
+}
+
+// SearchTime returns the timezone at the given latitude and longitude with the
+// UTC offset, abbreviation and daylight saving time of t in that timezone.
+// Returns ErrNoTimezone when no timezone is found.
+func (tzc *Timezonecache) SearchTime(lat, lng float64, t time.Time) (TimeResult, error) {
+	res, err := tzc.Search(lat, lng)
+	if err != nil {
+		return TimeResult{Result: res}, err
+	}
+	loc, err := tzc.loadLocation(res.Name)
+	if err != nil {
+		return TimeResult{Result: res}, err
+	}
+	t = t.In(loc)
+	abbr, offset := t.Zone()
+	return TimeResult{
+		Result:       res,
+		Location:     loc,
+		Time:         t,
+		Abbreviation: abbr,
+		Offset:       time.Duration(offset) * time.Second,
+		DST:          isDST(t),
+	}, nil
+}
+
+// loadLocation returns the cached *time.Location for name
+func (tzc *Timezonecache) loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, ErrNoTimezone
+	}
+	if loc, ok := tzc.locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := tzc.locations.LoadOrStore(name, loc)
+	return actual.(*time.Location), nil
+}
+
+// isDST returns true when t is in daylight saving time. The standard offset
+// is the smaller of the offsets on January 1st and July 1st of the same year.
+func isDST(t time.Time) bool {
+	_, offset := t.Zone()
+	_, jan := time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location()).Zone()
+	_, jul := time.Date(t.Year(), time.July, 1, 0, 0, 0, 0, t.Location()).Zone()
+	if jan == jul {
+		return false
+	}
+	std := jan
+	if jul < std {
+		std = jul
+	}
+	return offset > std
+}
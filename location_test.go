@@ -0,0 +1,64 @@
+package timezoneLookup
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+)
+
+func TestSearchTime(t *testing.T) {
+	tzc := new(Timezonecache)
+	tzc.AddTimezone(Timezone{Name: "Europe/Berlin", Polygons: []geo.Polygon{square(45, 5, 10)}})
+	tzc.AddTimezone(Timezone{Name: "Australia/Sydney", Polygons: []geo.Polygon{square(-40, 140, 10)}})
+	tzc.AddTimezone(Timezone{Name: "Asia/Tokyo", Polygons: []geo.Polygon{square(30, 130, 10)}})
+	tzc.AddTimezone(Timezone{Name: "America/New_York", Polygons: []geo.Polygon{square(35, -80, 10)}})
+	jan := time.Date(2022, time.January, 15, 12, 0, 0, 0, time.UTC)
+	jul := time.Date(2022, time.July, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		lat, lng float64
+		t        time.Time
+		name     string
+		abbr     string
+		offset   time.Duration
+		dst      bool
+	}{
+		{50, 10, jan, "Europe/Berlin", "CET", 1 * time.Hour, false},
+		{50, 10, jul, "Europe/Berlin", "CEST", 2 * time.Hour, true},
+		{-35, 145, jan, "Australia/Sydney", "AEDT", 11 * time.Hour, true},
+		{-35, 145, jul, "Australia/Sydney", "AEST", 10 * time.Hour, false},
+		{35, 135, jan, "Asia/Tokyo", "JST", 9 * time.Hour, false},
+		{35, 135, jul, "Asia/Tokyo", "JST", 9 * time.Hour, false},
+		{40, -75, jan, "America/New_York", "EST", -5 * time.Hour, false},
+		{40, -75, jul, "America/New_York", "EDT", -4 * time.Hour, true},
+		// the day daylight saving time ends in Europe
+		{50, 10, time.Date(2022, time.October, 30, 0, 59, 0, 0, time.UTC), "Europe/Berlin", "CEST", 2 * time.Hour, true},
+		{50, 10, time.Date(2022, time.October, 30, 1, 0, 0, 0, time.UTC), "Europe/Berlin", "CET", 1 * time.Hour, false},
+	}
+	for _, tt := range tests {
+		res, err := tzc.SearchTime(tt.lat, tt.lng, tt.t)
+		if err != nil {
+			t.Fatalf("SearchTime(%v, %v, %v): %v", tt.lat, tt.lng, tt.t, err)
+		}
+		if res.Name != tt.name || res.Abbreviation != tt.abbr || res.Offset != tt.offset || res.DST != tt.dst {
+			t.Errorf("SearchTime(%v, %v, %v) = %q %q %v DST %v, want %q %q %v DST %v", tt.lat, tt.lng, tt.t,
+				res.Name, res.Abbreviation, res.Offset, res.DST, tt.name, tt.abbr, tt.offset, tt.dst)
+		}
+		if res.Location == nil || res.Location.String() != tt.name || res.Time.Location() != res.Location || !res.Time.Equal(tt.t) {
+			t.Errorf("SearchTime(%v, %v, %v) = %v in %v", tt.lat, tt.lng, tt.t, res.Time, res.Location)
+		}
+	}
+
+	res, err := tzc.SearchTime(0, 0, jan)
+	if !errors.Is(err, ErrNoTimezone) || res.Name != "" || res.ZoneID != NoZone || res.Location != nil {
+		t.Fatalf("SearchTime(0, 0) = %q %v, %v, want %v", res.Name, res.Location, err, ErrNoTimezone)
+	}
+	if _, err := tzc.Location(0, 0); !errors.Is(err, ErrNoTimezone) {
+		t.Fatalf("Location(0, 0) = %v, want %v", err, ErrNoTimezone)
+	}
+	if _, err := tzc.SearchTime(91, 0, jan); !errors.Is(err, ErrCoordinatesNotValid) {
+		t.Fatalf("SearchTime(91, 0) = %v, want %v", err, ErrCoordinatesNotValid)
+	}
+}
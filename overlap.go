@@ -0,0 +1,144 @@
+package timezoneLookup
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+)
+
+var (
+	ErrAmbiguousTimezone = errors.New("error coordinates are within more than one timezone")
+)
+
+// TieBreak is the policy used by Search when coordinates are within
+// the polygons of more than one timezone.
+type TieBreak uint8
+
+// TieBreak policies
+const (
+	TieBreakFirst        TieBreak = iota // Timezone of the first polygon found in the RTree
+	TieBreakSmallestArea                 // Timezone of the polygon with the smallest area
+	TieBreakPriority                     // Timezone that is first in the priority list, otherwise TieBreakSmallestArea
+	TieBreakError                        // Return ErrAmbiguousTimezone
+)
+
+// SetTieBreak sets the policy used by Search for coordinates within the polygons
+// of more than one timezone. The priority list is used by TieBreakPriority,
+// which also applies to coordinates exactly on the border of timezones.
+func (tzc *Timezonecache) SetTieBreak(tb TieBreak, priority ...string) {
+	tzc.mu.Lock()
+	defer tzc.mu.Unlock()
+	tzc.tieBreak = tb
+	tzc.priority = make(map[string]int, len(priority))
+	for i, name := range priority {
+		if _, ok := tzc.priority[name]; !ok {
+			tzc.priority[name] = i
+		}
+	}
+}
+
+// SearchAll returns the names of all timezones with a polygon that contains the
+// given latitude and longitude, or with the coordinates exactly on its border,
+// sorted in lexicographical order.
+func (tzc *Timezonecache) SearchAll(lat, lng float64) ([]string, error) {
+	ll := geo.NewLatLng(lat, lng)
+	if !ll.Valid() {
+		return nil, ErrCoordinatesNotValid
+	}
+	if err := tzc.rlock(); err != nil {
+		return nil, err
+	}
+	defer tzc.mu.RUnlock()
+	var names []string
+	for _, id := range tzc.containing(ll) {
+		names = append(names, tzc.polygonZone(id))
+	}
+	return uniqueNames(names), nil
+}
+
+// containing returns the ids of all polygons that contain ll or have ll on their border
+func (tzc *Timezonecache) containing(ll geo.LatLng) []uint {
+	s := searcher{tzc: tzc, ll: ll}
+	tzc.searchLatLng(context.Background(), ll, s.all)
+	return append(s.ids, s.edge...)
+}
+
+// resolve returns the zone ID of the polygon ids according to the TieBreak policy.
+// Returns NoZone when ids is empty.
+func (tzc *Timezonecache) resolve(ids []uint) (uint32, error) {
+	if len(ids) == 0 {
+		return NoZone, nil
+	}
+	zone, ambiguous := tzc.zone[ids[0]], false
+	for _, id := range ids[1:] {
+		if tzc.zone[id] != zone {
+			ambiguous = true
+			break
+		}
+	}
+	if !ambiguous {
+		return zone, nil
+	}
+	switch tzc.tieBreak {
+	case TieBreakError:
+		return NoZone, ErrAmbiguousTimezone
+	case TieBreakFirst:
+		return zone, nil
+	}
+	best, bestArea := -1, 0.0
+	for i, id := range ids {
+		p := tzc.codec.DecodePolygon(tzc.buf(id), tzc.precision)
+		area := p.Area()
+		if best < 0 || tzc.less(tzc.polygonZone(id), area, tzc.polygonZone(ids[best]), bestArea) {
+			best, bestArea = i, area
+		}
+	}
+	return tzc.zone[ids[best]], nil
+}
+
+// resolveEdge returns the zone ID of the polygon ids with the searched coordinates
+// exactly on their border, which is independent of the order of ids. With
+// TieBreakPriority the timezone that is first in the priority list is preferred,
+// otherwise the lexicographically smallest timezone.
+func (tzc *Timezonecache) resolveEdge(ids []uint) uint32 {
+	zone := NoZone
+	for _, id := range ids {
+		if zone == NoZone || tzc.less(tzc.polygonZone(id), 0, tzc.zones[zone], 0) {
+			zone = tzc.zone[id]
+		}
+	}
+	return zone
+}
+
+// less returns true when timezone a with polygon area aArea is preferred over b
+func (tzc *Timezonecache) less(a string, aArea float64, b string, bArea float64) bool {
+	if tzc.tieBreak == TieBreakPriority {
+		pa, aok := tzc.priority[a]
+		pb, bok := tzc.priority[b]
+		if aok != bok {
+			return aok
+		}
+		if aok && pa != pb {
+			return pa < pb
+		}
+	}
+	if aArea != bArea {
+		return aArea < bArea
+	}
+	return a < b
+}
+
+// uniqueNames sorts names and removes duplicates
+func uniqueNames(names []string) []string {
+	sort.Strings(names)
+	j := 0
+	for i := range names {
+		if i == 0 || names[i] != names[j-1] {
+			names[j] = names[i]
+			j++
+		}
+	}
+	return names[:j]
+}
@@ -0,0 +1,127 @@
+package timezoneLookup
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+)
+
+// triangles returns the timezones North and South of the diagonal border from
+// a to b, which their polygons walk in opposite directions.
+func triangles(a, b geo.LatLng) []Timezone {
+	return []Timezone{
+		{Name: "North", Polygons: []geo.Polygon{geo.NewPolygonFromVertices([]geo.LatLng{a, b, {Lat: b.Lat, Lng: a.Lng}, a})}},
+		{Name: "South", Polygons: []geo.Polygon{geo.NewPolygonFromVertices([]geo.LatLng{b, a, {Lat: a.Lat, Lng: b.Lng}, b})}},
+	}
+}
+
+func TestSearchSharedBorder(t *testing.T) {
+	a, b := geo.NewLatLng(1.25, 2.5), geo.NewLatLng(3.75, 9.5)
+	tz := triangles(a, b)
+	var caches []*Timezonecache
+	for _, order := range [][]Timezone{tz, {tz[1], tz[0]}} {
+		tzc := new(Timezonecache)
+		for _, tz := range order {
+			tzc.AddTimezone(tz)
+		}
+		caches = append(caches, tzc, loadFile(t, saveFile(t, tzc)))
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		f := r.Float64()
+		lat, lng := a.Lat+f*(b.Lat-a.Lat), a.Lng+f*(b.Lng-a.Lng)
+		want, err := caches[0].Search(lat, lng)
+		if err != nil || want.Name == "" {
+			t.Fatalf("Search(%v, %v) = %q, %v", lat, lng, want.Name, err)
+		}
+		for _, tzc := range caches[1:] {
+			res, err := tzc.Search(lat, lng)
+			if err != nil || res.Name != want.Name || res.OnEdge != want.OnEdge {
+				t.Fatalf("Search(%v, %v) = %q on edge %v, %v, want %q on edge %v",
+					lat, lng, res.Name, res.OnEdge, err, want.Name, want.OnEdge)
+			}
+		}
+	}
+	// vertices of the border are on the edge of both
+	for _, tzc := range caches {
+		res, err := tzc.Search(a.Lat, a.Lng)
+		if err != nil || res.Name != "North" || !res.OnEdge {
+			t.Fatalf("Search(%v) = %q on edge %v, %v", a, res.Name, res.OnEdge, err)
+		}
+	}
+}
+
+// overlapping returns Timezonecaches with the nested timezones Big, Mid and
+// Small added in both orders, in memory and loaded.
+func overlapping(t *testing.T) []*Timezonecache {
+	tz := []Timezone{
+		{Name: "Big", Polygons: []geo.Polygon{square(0, 0, 10)}},
+		{Name: "Mid", Polygons: []geo.Polygon{square(1, 1, 5)}},
+		{Name: "Small", Polygons: []geo.Polygon{square(2, 2, 2)}},
+	}
+	var caches []*Timezonecache
+	for _, order := range [][]Timezone{tz, {tz[2], tz[0], tz[1]}} {
+		tzc := new(Timezonecache)
+		for _, tz := range order {
+			tzc.AddTimezone(tz)
+		}
+		caches = append(caches, tzc, loadFile(t, saveFile(t, tzc)))
+	}
+	return caches
+}
+
+func TestSearchAll(t *testing.T) {
+	tests := []struct {
+		lat, lng float64
+		want     []string
+	}{
+		{3, 3, []string{"Big", "Mid", "Small"}},
+		{2, 3, []string{"Big", "Mid", "Small"}}, // on the border of Small
+		{5, 5, []string{"Big", "Mid"}},
+		{8, 8, []string{"Big"}},
+		{20, 20, nil},
+	}
+	for i, tzc := range overlapping(t) {
+		for _, tt := range tests {
+			got, err := tzc.SearchAll(tt.lat, tt.lng)
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("cache %d: SearchAll(%v, %v) = %v, %v, want %v", i, tt.lat, tt.lng, got, err, tt.want)
+			}
+		}
+	}
+}
+
+func TestTieBreak(t *testing.T) {
+	tests := []struct {
+		tieBreak TieBreak
+		priority []string
+		lat, lng float64
+		want     string
+		err      error
+	}{
+		{TieBreakSmallestArea, nil, 3, 3, "Small", nil},
+		{TieBreakSmallestArea, nil, 5, 5, "Mid", nil},
+		{TieBreakSmallestArea, nil, 8, 8, "Big", nil},
+		{TieBreakPriority, []string{"Big"}, 3, 3, "Big", nil},
+		{TieBreakPriority, []string{"Mid", "Big"}, 3, 3, "Mid", nil},
+		{TieBreakPriority, []string{"Big", "Mid"}, 3, 3, "Big", nil},
+		{TieBreakPriority, []string{"Small"}, 5, 5, "Mid", nil}, // Small is not there
+		{TieBreakPriority, []string{"Other"}, 3, 3, "Small", nil},
+		{TieBreakPriority, nil, 5, 5, "Mid", nil},
+		{TieBreakError, nil, 3, 3, "", ErrAmbiguousTimezone},
+		{TieBreakError, nil, 8, 8, "Big", nil},
+	}
+	for i, tzc := range overlapping(t) {
+		for _, tt := range tests {
+			tzc.SetTieBreak(tt.tieBreak, tt.priority...)
+			res, err := tzc.Search(tt.lat, tt.lng)
+			if !errors.Is(err, tt.err) || res.Name != tt.want {
+				t.Fatalf("cache %d: TieBreak %d %v: Search(%v, %v) = %q, %v, want %q, %v",
+					i, tt.tieBreak, tt.priority, tt.lat, tt.lng, res.Name, err, tt.want, tt.err)
+			}
+		}
+	}
+}
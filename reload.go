@@ -0,0 +1,237 @@
+package timezoneLookup
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+)
+
+// Reloader is a Timezonecache that can be reloaded from its timezone database
+// file without interrupting lookups. Lookups that are in progress during a
+// reload finish on the previous Timezonecache, which is closed afterwards.
+//
+// Reloader is safe for concurrent use.
+type Reloader struct {
+	filename  string
+	configure func(tzc *Timezonecache)
+	onReload  func(err error)
+
+	mu  sync.RWMutex
+	cur *refCache
+}
+
+// refCache is a Timezonecache with a count of the lookups using it
+type refCache struct {
+	*Timezonecache
+	refs sync.WaitGroup
+	fi   os.FileInfo // of the loaded file
+}
+
+// NewReloader loads the timezone database filename and returns a Reloader for it.
+// configure is called for every loaded Timezonecache to set its options and can be nil.
+func NewReloader(filename string, configure func(tzc *Timezonecache)) (*Reloader, error) {
+	r := &Reloader{filename: filename, configure: configure}
+	c, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	r.cur = c
+	return r, nil
+}
+
+func (r *Reloader) load() (*refCache, error) {
+	f, err := os.Open(r.filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	c := &refCache{Timezonecache: new(Timezonecache)}
+	if c.fi, err = f.Stat(); err != nil {
+		return nil, err
+	}
+	if err = c.Load(f); err != nil {
+		return nil, err
+	}
+	if r.configure != nil {
+		r.configure(c.Timezonecache)
+	}
+	return c, nil
+}
+
+// Reload loads the timezone database file and swaps it in for new lookups.
+// Reload returns after the lookups that use the previous Timezonecache have
+// finished and it has been closed. The previous Timezonecache is kept when
+// the file can not be loaded.
+func (r *Reloader) Reload() error {
+	c, err := r.load()
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	old := r.cur
+	if old != nil {
+		r.cur = c
+	}
+	r.mu.Unlock()
+	if old == nil {
+		c.Close()
+		return ErrClosed
+	}
+	old.refs.Wait()
+	return old.Close()
+}
+
+// OnReload sets a function that is called with the result of every reload
+// triggered by WatchSignal or WatchFile.
+func (r *Reloader) OnReload(fn func(err error)) {
+	r.mu.Lock()
+	r.onReload = fn
+	r.mu.Unlock()
+}
+
+func (r *Reloader) reload() error {
+	err := r.Reload()
+	r.mu.RLock()
+	fn := r.onReload
+	r.mu.RUnlock()
+	if fn != nil {
+		fn(err)
+	}
+	return err
+}
+
+// WatchSignal reloads the timezone database every time one of the signals is received,
+// ex: syscall.SIGHUP. WatchSignal blocks until ctx is done.
+func (r *Reloader) WatchSignal(ctx context.Context, sig ...os.Signal) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sig...)
+	defer signal.Stop(ch)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ch:
+			r.reload()
+		}
+	}
+}
+
+// WatchFile checks the timezone database file every interval and reloads it when
+// it is not the loaded file or its modification time or size changed. A failed
+// reload is retried at the next interval. WatchFile blocks until ctx is done.
+func (r *Reloader) WatchFile(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		if fi, err := os.Stat(r.filename); err == nil && !r.loaded(fi) {
+			r.reload()
+		}
+	}
+}
+
+// loaded returns true when fi is the file of the current Timezonecache, or after Close
+func (r *Reloader) loaded(fi os.FileInfo) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cur == nil || (os.SameFile(r.cur.fi, fi) && fi.ModTime().Equal(r.cur.fi.ModTime()) && fi.Size() == r.cur.fi.Size())
+}
+
+// Close closes the Timezonecache once the lookups in progress have finished.
+// Lookups after Close return ErrClosed.
+func (r *Reloader) Close() error {
+	r.mu.Lock()
+	c := r.cur
+	r.cur = nil
+	r.mu.Unlock()
+	if c == nil {
+		return ErrClosed
+	}
+	c.refs.Wait()
+	return c.Close()
+}
+
+// acquire returns the current Timezonecache. It must be released after use.
+func (r *Reloader) acquire() (*refCache, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.cur == nil {
+		return nil, ErrClosed
+	}
+	r.cur.refs.Add(1)
+	return r.cur, nil
+}
+
+// View calls fn with the current Timezonecache. The Timezonecache is not
+// closed by a reload until fn returns and must not be used afterwards.
+func (r *Reloader) View(fn func(tzc *Timezonecache) error) error {
+	c, err := r.acquire()
+	if err != nil {
+		return err
+	}
+	defer c.refs.Done()
+	return fn(c.Timezonecache)
+}
+
+// Search is Timezonecache.Search on the current Timezonecache
+func (r *Reloader) Search(lat, lng float64) (Result, error) {
+	return r.SearchContext(context.Background(), lat, lng)
+}
+
+// SearchContext is Timezonecache.SearchContext on the current Timezonecache
+func (r *Reloader) SearchContext(ctx context.Context, lat, lng float64) (Result, error) {
+	c, err := r.acquire()
+	if err != nil {
+		return Result{ZoneID: NoZone}, err
+	}
+	defer c.refs.Done()
+	return c.SearchContext(ctx, lat, lng)
+}
+
+// SearchBatchContext is Timezonecache.SearchBatchContext on the current Timezonecache
+func (r *Reloader) SearchBatchContext(ctx context.Context, points []geo.LatLng, out []Result) error {
+	c, err := r.acquire()
+	if err != nil {
+		return err
+	}
+	defer c.refs.Done()
+	return c.SearchBatchContext(ctx, points, out)
+}
+
+// SearchAll is Timezonecache.SearchAll on the current Timezonecache
+func (r *Reloader) SearchAll(lat, lng float64) ([]string, error) {
+	c, err := r.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer c.refs.Done()
+	return c.SearchAll(lat, lng)
+}
+
+// Location is Timezonecache.Location on the current Timezonecache
+func (r *Reloader) Location(lat, lng float64) (*time.Location, error) {
+	c, err := r.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer c.refs.Done()
+	return c.Location(lat, lng)
+}
+
+// Info returns the metadata of the current timezone database
+func (r *Reloader) Info() Info {
+	c, err := r.acquire()
+	if err != nil {
+		return Info{}
+	}
+	defer c.refs.Done()
+	return c.Info()
+}
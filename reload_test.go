@@ -0,0 +1,207 @@
+package timezoneLookup
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+)
+
+// newReloadCache returns a Timezonecache with the timezone name at 5, 5 and
+// the timezones C and E apart from it.
+func newReloadCache(name string) *Timezonecache {
+	tzc := new(Timezonecache)
+	tzc.AddTimezone(Timezone{Name: name, Polygons: []geo.Polygon{square(0, 0, 10)}})
+	tzc.AddTimezone(Timezone{Name: "C", Polygons: []geo.Polygon{square(20, 20, 5)}})
+	if name != "A" {
+		tzc.AddTimezone(Timezone{Name: "E", Polygons: []geo.Polygon{square(40, 40, 5)}})
+	}
+	return tzc
+}
+
+// saveReload saves the timezone data with the timezone name at 5, 5 to filename
+func saveReload(t *testing.T, filename, name string) {
+	t.Helper()
+	if err := newReloadCache(name).Save(filename); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestReloaderConcurrent runs Search and View while the Reloader is reloaded
+// and closed. Run with -race.
+func TestReloaderConcurrent(t *testing.T) {
+	filename := saveFile(t, newReloadCache("A"))
+	r, err := NewReloader(filename, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errc := make(chan error, 16)
+	for w := 0; w < 4; w++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for {
+				res, err := r.Search(5, 5)
+				if errors.Is(err, ErrClosed) {
+					return
+				}
+				if err != nil || (res.Name != "A" && res.Name != "D") {
+					errc <- errors.New("Search returned " + res.Name)
+					return
+				}
+				runtime.Gosched()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for {
+				err := r.View(func(tzc *Timezonecache) error {
+					// a reload does not close tzc until the view returns
+					first, err := tzc.Search(5, 5)
+					for i := 0; i < 10 && err == nil; i++ {
+						var res Result
+						if res, err = tzc.Search(5, 5); err == nil && res.Name != first.Name {
+							err = errors.New("View searched " + first.Name + " and " + res.Name)
+						}
+					}
+					return err
+				})
+				if errors.Is(err, ErrClosed) {
+					return
+				}
+				if err != nil {
+					errc <- err
+					return
+				}
+				runtime.Gosched()
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		saveReload(t, filename, [...]string{"A", "D"}[i%2])
+		if err := r.Reload(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	close(errc)
+	for err := range errc {
+		t.Error(err)
+	}
+
+	if _, err := r.Search(5, 5); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Search after Close: %v", err)
+	}
+	if err := r.View(func(*Timezonecache) error { return nil }); !errors.Is(err, ErrClosed) {
+		t.Fatalf("View after Close: %v", err)
+	}
+	if err := r.Reload(); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Reload after Close: %v", err)
+	}
+	if err := r.Close(); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Close after Close: %v", err)
+	}
+}
+
+// TestReloadView tests that a lookup in progress during a reload finishes on the
+// previous Timezonecache while new lookups use the reloaded one.
+func TestReloadView(t *testing.T) {
+	filename := saveFile(t, newReloadCache("A"))
+	r, err := NewReloader(filename, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	viewing, release, viewed := make(chan struct{}), make(chan struct{}), make(chan error)
+	go func() {
+		viewed <- r.View(func(tzc *Timezonecache) error {
+			close(viewing)
+			<-release
+			res, err := tzc.Search(5, 5)
+			if err == nil && res.Name != "A" {
+				err = errors.New("View searched " + res.Name + " after the reload")
+			}
+			return err
+		})
+	}()
+	<-viewing
+	saveReload(t, filename, "D")
+	reloaded := make(chan error)
+	go func() { reloaded <- r.Reload() }()
+	for {
+		res, err := r.Search(5, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Name == "D" {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case err := <-reloaded:
+		t.Fatalf("Reload returned %v before the view finished", err)
+	default:
+	}
+	close(release)
+	if err := <-viewed; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-reloaded; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatchFile(t *testing.T) {
+	filename := saveFile(t, newReloadCache("A"))
+	r, err := NewReloader(filename, func(tzc *Timezonecache) { tzc.SetNauticalFallback(true) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	reloads := make(chan error, 1)
+	r.OnReload(func(err error) { reloads <- err })
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.WatchFile(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	saveReload(t, filename, "D")
+	select {
+	case err := <-reloads:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the file was not reloaded")
+	}
+	expectReload(t, r, 5, 5, "D")
+	expectReload(t, r, 42, 42, "E")
+	// configured for every reload
+	expectReload(t, r, -50, 0, "Etc/GMT")
+}
+
+// expectReload fails the test when the timezone at lat, lng is not name
+func expectReload(t *testing.T, r *Reloader, lat, lng float64, name string) {
+	t.Helper()
+	res, err := r.Search(lat, lng)
+	if err != nil || res.Name != name {
+		t.Fatalf("Search(%v, %v) = %q, %v, want %q", lat, lng, res.Name, err, name)
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2018-2022 Evan Oberholster.
+//
+// SPDX-License-Identifier: MIT
+
+// Package server provides HTTP handlers for timezone lookups that
+// can be mounted on any http.ServeMux.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	timezone "github.com/evanoberholster/timezoneLookup/v2"
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+)
+
+const (
+	// MaxBatch is the maximum number of coordinates of a batch request
+	MaxBatch = 10000
+
+	maxBodySize = MaxBatch * 64
+)
+
+// Cache is a timezone database that is served by the Handler.
+// *timezone.Timezonecache satisfies Cache.
+type Cache interface {
+	SearchContext(ctx context.Context, lat, lng float64) (timezone.Result, error)
+	SearchBatchContext(ctx context.Context, points []geo.LatLng, out []timezone.Result) error
+	Info() timezone.Info
+}
+
+// Handler serves the following endpoints:
+//
+//	GET  /v1/timezone?lat=&lng=  timezone of the coordinates
+//	POST /v1/timezone            timezones of a JSON array of {"lat":0,"lng":0} coordinates
+//	GET  /v1/info                timezone database metadata
+//	GET  /healthz                health check
+type Handler struct {
+	cache Cache
+	mux   *http.ServeMux
+}
+
+// New returns a new Handler for the Cache
+func New(cache Cache) *Handler {
+	h := &Handler{cache: cache, mux: http.NewServeMux()}
+	h.mux.HandleFunc("/v1/timezone", h.timezone)
+	h.mux.HandleFunc("/v1/info", h.info)
+	h.mux.HandleFunc("/healthz", h.healthz)
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// Coordinates are the coordinates of a batch request
+type Coordinates struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// Response is the JSON response of a timezone lookup
+type Response struct {
+	Timezone    string  `json:"timezone"`
+	Lat         float64 `json:"lat"`
+	Lng         float64 `json:"lng"`
+	Source      string  `json:"source"`
+	Distance    float64 `json:"distance,omitempty"`
+	Approximate bool    `json:"approximate,omitempty"`
+	OnEdge      bool    `json:"on_edge,omitempty"`
+}
+
+// InfoResponse is the JSON response of the info endpoint
+type InfoResponse struct {
+	Version   uint16    `json:"version"`
+	Release   string    `json:"release"`
+	Created   time.Time `json:"created"`
+	Checksum  uint32    `json:"checksum"`
+	Polygons  int       `json:"polygons"`
+	Zones     int       `json:"zones"`
+	Precision string    `json:"precision"`
+	Codec     string    `json:"codec"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func newResponse(res timezone.Result) Response {
+	return Response{
+		Timezone:    res.Name,
+		Lat:         res.Coordinates.Lat,
+		Lng:         res.Coordinates.Lng,
+		Source:      res.Source.String(),
+		Distance:    res.Distance,
+		Approximate: res.Approximate,
+		OnEdge:      res.OnEdge,
+	}
+}
+
+func (h *Handler) timezone(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		h.search(w, r)
+	case http.MethodPost:
+		h.searchBatch(w, r)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST")
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+	}
+}
+
+func (h *Handler) search(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	lat, err := strconv.ParseFloat(q.Get("lat"), 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid lat: %q", q.Get("lat")))
+		return
+	}
+	lng, err := strconv.ParseFloat(q.Get("lng"), 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid lng: %q", q.Get("lng")))
+		return
+	}
+	res, err := h.cache.SearchContext(r.Context(), lat, lng)
+	if err == nil && res.Name == "" {
+		err = timezone.ErrNoTimezone
+	}
+	if err != nil {
+		writeError(w, statusCode(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newResponse(res))
+}
+
+func (h *Handler) searchBatch(w http.ResponseWriter, r *http.Request) {
+	var coords []Coordinates
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err := dec.Decode(&coords); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if len(coords) > MaxBatch {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("batch is larger than %d coordinates", MaxBatch))
+		return
+	}
+	points := make([]geo.LatLng, len(coords))
+	for i, c := range coords {
+		if points[i] = geo.NewLatLng(c.Lat, c.Lng); !points[i].Valid() {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("point %d: %w", i, timezone.ErrCoordinatesNotValid))
+			return
+		}
+	}
+	results := make([]timezone.Result, len(points))
+	err := h.cache.SearchBatchContext(r.Context(), points, results)
+	// coordinates without a single timezone are returned with an empty timezone
+	if err != nil && !errors.Is(err, timezone.ErrNoTimezone) && !errors.Is(err, timezone.ErrAmbiguousTimezone) {
+		writeError(w, statusCode(err), err)
+		return
+	}
+	resp := make([]Response, len(results))
+	for i, res := range results {
+		resp[i] = newResponse(res)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) info(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	info := h.cache.Info()
+	writeJSON(w, http.StatusOK, InfoResponse{
+		Version:   info.Version,
+		Release:   info.Release,
+		Created:   info.Created,
+		Checksum:  info.Checksum,
+		Polygons:  info.Polygons,
+		Zones:     info.Zones,
+		Precision: info.Precision.String(),
+		Codec:     info.Codec.String(),
+	})
+}
+
+func (h *Handler) healthz(w http.ResponseWriter, r *http.Request) {
+	if h.cache.Info().Polygons == 0 {
+		writeError(w, http.StatusServiceUnavailable, errors.New("timezone database is empty"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok\n"))
+}
+
+// statusCode returns the HTTP status code of a lookup error
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, timezone.ErrCoordinatesNotValid):
+		return http.StatusBadRequest
+	case errors.Is(err, timezone.ErrNoTimezone):
+		return http.StatusNotFound
+	case errors.Is(err, timezone.ErrAmbiguousTimezone):
+		return http.StatusConflict
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,150 @@
+// Copyright 2018-2022 Evan Oberholster.
+//
+// SPDX-License-Identifier: MIT
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	timezone "github.com/evanoberholster/timezoneLookup/v2"
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+)
+
+// newTestHandler returns a Handler of a Timezonecache with the timezone
+// Europe/Berlin from 0,0 to 10,10.
+func newTestHandler() *Handler {
+	tzc := new(timezone.Timezonecache)
+	tzc.SetRelease("2022a")
+	tzc.AddTimezone(timezone.Timezone{Name: "Europe/Berlin", Polygons: []geo.Polygon{geo.NewPolygonFromVertices([]geo.LatLng{
+		{Lat: 0, Lng: 0}, {Lat: 0, Lng: 10}, {Lat: 10, Lng: 10}, {Lat: 10, Lng: 0}, {Lat: 0, Lng: 0},
+	})}})
+	return New(tzc)
+}
+
+func serve(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return w
+}
+
+func TestSearch(t *testing.T) {
+	h := newTestHandler()
+	tests := []struct {
+		name   string
+		method string
+		target string
+		code   int
+	}{
+		{"found", http.MethodGet, "/v1/timezone?lat=5&lng=5", http.StatusOK},
+		{"head", http.MethodHead, "/v1/timezone?lat=5&lng=5", http.StatusOK},
+		{"missing lat", http.MethodGet, "/v1/timezone?lng=5", http.StatusBadRequest},
+		{"invalid lng", http.MethodGet, "/v1/timezone?lat=5&lng=east", http.StatusBadRequest},
+		{"lat out of range", http.MethodGet, "/v1/timezone?lat=91&lng=5", http.StatusBadRequest},
+		{"lng out of range", http.MethodGet, "/v1/timezone?lat=5&lng=-180.5", http.StatusBadRequest},
+		{"no timezone", http.MethodGet, "/v1/timezone?lat=-45&lng=-45", http.StatusNotFound},
+		{"method", http.MethodDelete, "/v1/timezone?lat=5&lng=5", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(h, tt.method, tt.target, "")
+			if w.Code != tt.code {
+				t.Fatalf("%s %s = %d, want %d: %s", tt.method, tt.target, w.Code, tt.code, w.Body)
+			}
+			if got := w.Header().Get("Content-Type"); got != "application/json" {
+				t.Fatalf("Content-Type = %q", got)
+			}
+			if tt.method == http.MethodHead {
+				return
+			}
+			if tt.code != http.StatusOK {
+				var resp errorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Error == "" {
+					t.Fatalf("error response %q: %v", w.Body, err)
+				}
+				return
+			}
+			var resp Response
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Timezone != "Europe/Berlin" || resp.Lat != 5 || resp.Lng != 5 || resp.Source != "polygon" {
+				t.Fatalf("response %+v", resp)
+			}
+		})
+	}
+	if w := serve(h, http.MethodPut, "/v1/timezone", ""); w.Header().Get("Allow") != "GET, HEAD, POST" {
+		t.Fatalf("Allow = %q", w.Header().Get("Allow"))
+	}
+}
+
+func TestSearchBatch(t *testing.T) {
+	h := newTestHandler()
+	w := serve(h, http.MethodPost, "/v1/timezone", `[{"lat":5,"lng":5},{"lat":-45,"lng":-45},{"lat":1,"lng":9}]`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("batch = %d: %s", w.Code, w.Body)
+	}
+	var resp []Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Europe/Berlin", "", "Europe/Berlin"}
+	if len(resp) != len(want) {
+		t.Fatalf("%d results, want %d", len(resp), len(want))
+	}
+	for i := range want {
+		if resp[i].Timezone != want[i] {
+			t.Fatalf("result %d = %q, want %q", i, resp[i].Timezone, want[i])
+		}
+	}
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"empty", `[]`, http.StatusOK},
+		{"invalid body", `{"lat":5}`, http.StatusBadRequest},
+		{"invalid point", `[{"lat":5,"lng":5},{"lat":95,"lng":5}]`, http.StatusBadRequest},
+		{"too large", "[" + strings.Repeat(`{"lat":5,"lng":5},`, MaxBatch) + `{"lat":5,"lng":5}]`, http.StatusRequestEntityTooLarge},
+		{"max", "[" + strings.Repeat(`{"lat":5,"lng":5},`, MaxBatch-1) + `{"lat":5,"lng":5}]`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := serve(h, http.MethodPost, "/v1/timezone", tt.body); w.Code != tt.code {
+				t.Fatalf("batch = %d, want %d: %.200s", w.Code, tt.code, w.Body)
+			}
+		})
+	}
+}
+
+func TestInfo(t *testing.T) {
+	h := newTestHandler()
+	w := serve(h, http.MethodGet, "/v1/info", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("info = %d: %s", w.Code, w.Body)
+	}
+	var resp InfoResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Release != "2022a" || resp.Polygons != 1 || resp.Zones != 1 || resp.Precision != "float32" || resp.Codec != "raw" {
+		t.Fatalf("info %+v", resp)
+	}
+	if w := serve(h, http.MethodPost, "/v1/info", ""); w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST info = %d", w.Code)
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	if w := serve(newTestHandler(), http.MethodGet, "/healthz", ""); w.Code != http.StatusOK || w.Body.String() != "ok\n" {
+		t.Fatalf("healthz = %d %q", w.Code, w.Body)
+	}
+	if w := serve(New(new(timezone.Timezonecache)), http.MethodGet, "/healthz", ""); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("empty healthz = %d", w.Code)
+	}
+}
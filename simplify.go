@@ -0,0 +1,33 @@
+package timezoneLookup
+
+import (
+	"errors"
+	"math"
+
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+)
+
+var (
+	ErrToleranceNotValid = errors.New("error simplification tolerance is not valid")
+)
+
+// Simplify simplifies the polygons of the Timezonecache with a tolerance in
+// meters, see geo.SimplifyPolygons. Borders shared by neighbouring timezones
+// stay coincident so that no gaps or overlaps open up between them. Simplify is
+// used once all timezones have been added and before Save. When the timezone
+// data was loaded the polygons are copied into memory.
+func (tzc *Timezonecache) Simplify(tolerance float64) error {
+	if math.IsNaN(tolerance) || math.IsInf(tolerance, 0) || tolerance < 0 {
+		return ErrToleranceNotValid
+	}
+	tzc.mu.Lock()
+	defer tzc.mu.Unlock()
+	if tzc.state == stateClosed {
+		return ErrClosed
+	}
+	if tolerance == 0 {
+		return nil
+	}
+	tzc.setPolygons(geo.SimplifyPolygons(tzc.polygons(), tolerance))
+	return nil
+}
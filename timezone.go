@@ -2,9 +2,16 @@ package timezoneLookup
 
 import (
 	"bufio"
+	"context"
 	"encoding/binary"
 	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
 	"os"
+	"path/filepath"
+	"sync"
 	"time"
 
 	mmapgo "github.com/edsrzf/mmap-go"
@@ -14,222 +21,612 @@ import (
 var (
 	endian                 = binary.LittleEndian
 	ErrCoordinatesNotValid = errors.New("Latitude and/or Longitude are not valid")
+
+	// Errors returned by Load when the timezone data is not valid
+	ErrInvalidMagic       = errors.New("error timezone data has an invalid magic number")
+	ErrUnsupportedVersion = errors.New("error timezone data format version is not supported")
+	ErrChecksumMismatch   = errors.New("error timezone data checksum mismatch")
+	ErrTruncated          = errors.New("error timezone data is truncated")
+	ErrCorrupt            = errors.New("error timezone data is corrupt")
+
+	// ErrTooLarge is returned by Save when the timezone data exceeds the limits of the format
+	ErrTooLarge = errors.New("error timezone data is too large")
+
+	// ErrClosed is returned when the Timezonecache is used after Close
+	ErrClosed = errors.New("error timezone data is closed")
+)
+
+const (
+	headerMagic   = "TZLOOKUP"
+	headerSize    = 48 // fixed length of the header, excluding the release
+	itemSize      = 8  // length of an item: [4]offset [4]name index
+	formatVersion = 5
 )
 
+// state of a Timezonecache
+type state uint8
+
+const (
+	stateOpen   state = iota // Timezone data can be added, loaded and searched
+	stateClosed              // Timezone data has been released by Close
+)
+
+// Timezonecache is a timezone database that is built with AddTimezone or loaded
+// with Load, LoadBytes, LoadFS or LoadReaderAt.
+//
+// Timezonecache is safe for concurrent use. Searches run concurrently with each
+// other, while AddTimezone, Load, Save, Close and the option setters wait for
+// the searches in progress to finish. Searches after Close return ErrClosed.
 type Timezonecache struct {
+	mu         sync.RWMutex
+	state      state
 	data       mmapgo.MMap
-	arr        []uint32
-	name       []string
+	arr        []uint32          // end offset of every polygon in the polygon data
+	zone       []uint32          // zone ID of every polygon
+	zones      []string          // unique timezone names by zone ID
+	zoneIDs    map[string]uint32 // zone ID by timezone name
 	rt         geo.RTree
+	tree       geo.PackedRTree
+	dataSize   int64 // length of the polygon data
 	dataOffset uint32
 	dataLength uint32
+	treeLength uint32
 	bufOffset  int64
+	precision  geo.Precision // encoding of the polygon vertices
+	codec      geo.Codec     // encoding of the polygon data
+	mapped     bool
+	release    string
+	created    time.Time
+	checksum   uint32
+	locations  sync.Map // *time.Location cache by timezone name
+	nearest    float64  // nearest timezone fallback radius in meters
+	nautical   bool     // nautical timezone fallback
+	tieBreak   TieBreak
+	priority   map[string]int // timezone priority for TieBreakPriority
+	workers    int            // goroutines used by SearchBatch
 }
 
+// Info describes a timezone database
+type Info struct {
+	Version   uint16        // Format version of the database
+	Release   string        // timezone-boundary-builder release. ex: "2020d"
+	Created   time.Time     // Time the database was built
+	Checksum  uint32        // CRC-32 (IEEE) of the polygon data and rtree
+	Polygons  int           // Number of polygons
+	Zones     int           // Number of unique timezones
+	Precision geo.Precision // Encoding of the polygon vertices
+	Codec     geo.Codec     // Encoding of the polygon data
+}
+
+// Info returns the metadata of the timezone database
+func (tzc *Timezonecache) Info() Info {
+	tzc.mu.RLock()
+	defer tzc.mu.RUnlock()
+	return Info{
+		Version:   formatVersion,
+		Release:   tzc.release,
+		Created:   tzc.created,
+		Checksum:  tzc.checksum,
+		Polygons:  len(tzc.arr),
+		Zones:     len(tzc.zones),
+		Precision: tzc.precision,
+		Codec:     tzc.codec,
+	}
+}
+
+// SetRelease sets the timezone-boundary-builder release that is
+// recorded in the header by Save. ex: "2020d"
+func (tzc *Timezonecache) SetRelease(release string) {
+	tzc.mu.Lock()
+	tzc.release = release
+	tzc.mu.Unlock()
+}
+
+// SetPrecision sets the precision of the polygon vertices that are added with
+// AddTimezone and saved by Save. Defaults to geo.Float32. The precision can
+// not be changed once polygons have been added.
+func (tzc *Timezonecache) SetPrecision(prec geo.Precision) error {
+	tzc.mu.Lock()
+	defer tzc.mu.Unlock()
+	if !prec.Valid() {
+		return geo.ErrPrecisionNotValid
+	}
+	if tzc.state == stateClosed {
+		return ErrClosed
+	}
+	if len(tzc.arr) > 0 && prec != tzc.precision {
+		return errors.New("error precision can not be changed after polygons are added")
+	}
+	tzc.precision = prec
+	return nil
+}
+
+// SetCodec sets the codec of the polygon data that is saved by Save. Defaults
+// to geo.Raw. The polygons that have already been added or loaded are re-encoded
+// with the codec and copied into memory.
+func (tzc *Timezonecache) SetCodec(codec geo.Codec) error {
+	tzc.mu.Lock()
+	defer tzc.mu.Unlock()
+	if !codec.Valid() {
+		return geo.ErrCodecNotValid
+	}
+	if tzc.state == stateClosed {
+		return ErrClosed
+	}
+	if codec == tzc.codec {
+		return nil
+	}
+	pp := tzc.polygons()
+	tzc.codec = codec
+	tzc.setPolygons(pp)
+	return nil
+}
+
+// AddTimezone adds the polygons of tz to the Timezonecache. The vertices are
+// rounded to the precision set with SetPrecision and encoded with the codec set
+// with SetCodec. Loaded timezone data is copied into memory first. AddTimezone
+// does nothing after Close.
 func (tzc *Timezonecache) AddTimezone(tz Timezone) {
+	tzc.mu.Lock()
+	defer tzc.mu.Unlock()
+	if len(tz.Polygons) == 0 || tzc.state == stateClosed {
+		return
+	}
+	if tzc.tree.Len() > 0 || tzc.mapped || tzc.bufOffset != 0 {
+		// loaded polygon data is followed by the packed RTree
+		tzc.setPolygons(tzc.polygons())
+	}
+	zone := tzc.addZone(tz.Name)
 	for _, p := range tz.Polygons {
-		var offset uint32
 		id := uint(len(tzc.arr)) // next id
-		buf := p.ToByteSlice()
+		buf := tzc.codec.Encode(p, tzc.precision)
 		tzc.data = append(tzc.data, buf...)
-		if id == 0 {
-			offset += tzc.dataOffset
-		} else {
-			offset += tzc.arr[id-1]
-		}
-		offset += uint32(len(buf))
-		tzc.arr = append(tzc.arr, offset)
-		tzc.name = append(tzc.name, tz.Name)
-		tzc.rt.InsertPolygon(p, id)
+		tzc.dataSize += int64(len(buf))
+		// offsets past math.MaxUint32 wrap and are rejected by Save
+		tzc.arr = append(tzc.arr, uint32(tzc.dataSize))
+		tzc.zone = append(tzc.zone, zone)
+		// the bounds of the rounded vertices
+		tzc.rt.InsertPolygon(tzc.codec.DecodePolygon(buf, tzc.precision), id)
 	}
 }
 
+// polygons returns all polygons decoded
+func (tzc *Timezonecache) polygons() []geo.Polygon {
+	pp := make([]geo.Polygon, len(tzc.arr))
+	for id := range pp {
+		pp[id] = tzc.codec.DecodePolygon(tzc.buf(uint(id)), tzc.precision)
+	}
+	return pp
+}
+
+// setPolygons replaces the polygon data with pp encoded with the codec, in
+// memory, and rebuilds the RTree from the bounds of the encoded polygons.
+func (tzc *Timezonecache) setPolygons(pp []geo.Polygon) {
+	var data []byte
+	arr := make([]uint32, 0, len(pp))
+	tzc.rt = geo.RTree{}
+	for id, p := range pp {
+		buf := tzc.codec.Encode(p, tzc.precision)
+		data = append(data, buf...)
+		arr = append(arr, uint32(len(data)))
+		tzc.rt.InsertPolygon(tzc.codec.DecodePolygon(buf, tzc.precision), uint(id))
+	}
+	if tzc.mapped {
+		munmap(tzc.data)
+	}
+	tzc.data, tzc.mapped, tzc.arr = data, false, arr
+	tzc.bufOffset, tzc.dataSize = 0, int64(len(data))
+	tzc.tree = geo.PackedRTree{}
+}
+
+// buf returns the encoded polygon id, or nil when there is no polygon id
 func (tzc *Timezonecache) buf(id uint) []byte {
 	offset := uint32(tzc.bufOffset)
-	if id == 0 {
+	switch {
+	case id >= uint(len(tzc.arr)):
+		return nil
+	case id == 0:
 		return tzc.data[offset : offset+tzc.arr[id]]
 	}
-	if id <= uint(len(tzc.arr)) {
-		return tzc.data[offset+tzc.arr[id-1] : offset+tzc.arr[id]]
-	}
-	return nil
+	return tzc.data[offset+tzc.arr[id-1] : offset+tzc.arr[id]]
 }
 
 func (tzc *Timezonecache) Search(lat, lng float64) (Result, error) {
-	var name string
+	return tzc.SearchContext(context.Background(), lat, lng)
+}
+
+// SearchContext is Search with a context. Searching stops at the next RTree node
+// or polygon when ctx is done and ctx.Err() is returned.
+func (tzc *Timezonecache) SearchContext(ctx context.Context, lat, lng float64) (Result, error) {
 	start := time.Now()
-	ll := geo.NewLatLng(lat, lng)
+	if err := tzc.rlock(); err != nil {
+		return Result{ZoneID: NoZone}, err
+	}
+	defer tzc.mu.RUnlock()
+	s := searcher{tzc: tzc, ctx: ctx}
+	res, err := s.search(geo.NewLatLng(lat, lng))
+	res.Elapsed = time.Since(start)
+	return res, err
+}
+
+// rlock locks the Timezonecache for searching. Returns ErrClosed without
+// holding the lock when the Timezonecache is closed.
+func (tzc *Timezonecache) rlock() error {
+	tzc.mu.RLock()
+	if tzc.state == stateClosed {
+		tzc.mu.RUnlock()
+		return ErrClosed
+	}
+	return nil
+}
+
+// searcher holds the state of the lookups of a single goroutine. The decoded
+// polygon and the polygon ids are reused between lookups.
+type searcher struct {
+	tzc  *Timezonecache
+	ctx  context.Context
+	ll   geo.LatLng
+	p    geo.Polygon
+	ids  []uint
+	edge []uint // polygons with ll exactly on their border
+	zone uint32
+}
+
+// search returns the timezone at ll. Coordinates exactly on the border of
+// polygons are only resolved with resolveEdge when no polygon contains them.
+func (s *searcher) search(ll geo.LatLng) (Result, error) {
 	if !ll.Valid() {
-		return Result{}, ErrCoordinatesNotValid
+		return Result{ZoneID: NoZone}, ErrCoordinatesNotValid
+	}
+	s.ll, s.zone, s.edge = ll, NoZone, s.edge[:0]
+	if s.tzc.tieBreak == TieBreakFirst {
+		if err := s.tzc.searchLatLng(s.ctx, ll, s.first); err != nil {
+			return Result{Coordinates: ll, ZoneID: NoZone}, err
+		}
+	} else {
+		s.ids = s.ids[:0]
+		err := s.tzc.searchLatLng(s.ctx, ll, s.all)
+		if err == nil {
+			s.zone, err = s.tzc.resolve(s.ids)
+		}
+		if err != nil {
+			return Result{Coordinates: ll, ZoneID: NoZone}, err
+		}
+	}
+	var onEdge bool
+	if s.zone == NoZone && len(s.edge) > 0 {
+		s.zone, onEdge = s.tzc.resolveEdge(s.edge), true
+	}
+	if s.zone == NoZone {
+		return s.tzc.fallback(s.ctx, ll)
+	}
+	return Result{Name: s.tzc.zones[s.zone], ZoneID: s.zone, Coordinates: ll, OnEdge: onEdge, Source: SourcePolygon}, nil
+}
+
+// location returns the location of the searched LatLng relative to polygon id
+func (s *searcher) location(id uint) geo.Location {
+	s.tzc.codec.Decode(&s.p, s.tzc.buf(id), s.tzc.precision)
+	return s.p.ContainsLatLngWithBoundary(s.ll)
+}
+
+// first is a searchLatLng iterator that stops at the first polygon that contains the searched LatLng
+func (s *searcher) first(id uint) bool {
+	switch s.location(id) {
+	case geo.Inside:
+		s.zone = s.tzc.zone[id]
+		return false // stop searching
+	case geo.OnEdge:
+		s.edge = append(s.edge, id)
+	}
+	return true
+}
+
+// all is a searchLatLng iterator that collects all polygons that contain the searched LatLng
+func (s *searcher) all(id uint) bool {
+	switch s.location(id) {
+	case geo.Inside:
+		s.ids = append(s.ids, id)
+	case geo.OnEdge:
+		s.edge = append(s.edge, id)
 	}
+	return true
+}
 
-	tzc.rt.SearchLatLng(ll, func(min geo.LatLng, max geo.LatLng, value interface{}) bool {
+// searchLatLng iterates the ids of the polygons with a bounding box that contains ll.
+// Searching stops when iter returns false or ctx is done.
+func (tzc *Timezonecache) searchLatLng(ctx context.Context, ll geo.LatLng, iter func(id uint) bool) error {
+	return tzc.searchBounds(ctx, ll, ll, iter)
+}
+
+// searchBounds iterates the ids of the polygons with a bounding box that intersects min and max.
+// The packed RTree is used when the timezone data was loaded, otherwise the RTree
+// built by AddTimezone is used. Searching stops when iter returns false or when ctx
+// is done, in which case ctx.Err() is returned.
+func (tzc *Timezonecache) searchBounds(ctx context.Context, min, max geo.LatLng, iter func(id uint) bool) error {
+	if tzc.tree.Len() > 0 {
+		return tzc.tree.SearchContext(ctx, min, max, func(id uint32) bool {
+			return iter(uint(id)) && ctx.Err() == nil
+		})
+	}
+	tzc.rt.SearchBounds(min, max, func(min, max geo.LatLng, value interface{}) bool {
 		if id, ok := value.(uint); ok {
-			p := geo.NewPolygon()
-			p.FromByteSlice(tzc.buf(id))
-			if p.ContainsLatLng(ll) {
-				name = tzc.name[id]
-				return true
-			}
+			return iter(id) && ctx.Err() == nil
 		}
-		return false
+		return true
 	})
-	return Result{Name: name, Coordinates: ll, Elapsed: time.Since(start)}, nil
+	return ctx.Err()
+}
+
+// packRtree returns the serialized PackedRTree of all polygons
+func (tzc *Timezonecache) packRtree() []byte {
+	items := make([]geo.PackedItem, 0, len(tzc.arr))
+	if tzc.tree.Len() > 0 {
+		tzc.tree.Scan(func(min, max geo.LatLng, id uint32) bool {
+			items = append(items, geo.PackedItem{Min: min, Max: max, ID: id})
+			return true
+		})
+	} else {
+		tzc.rt.Scan(func(min, max [2]float32, data interface{}) bool {
+			if id, ok := data.(uint); ok {
+				items = append(items, geo.PackedItem{
+					Min: geo.LatLng{Lat: float64(min[0]), Lng: float64(min[1])},
+					Max: geo.LatLng{Lat: float64(max[0]), Lng: float64(max[1])},
+					ID:  uint32(id),
+				})
+			}
+			return true
+		})
+	}
+	return geo.PackRTree(items, geo.DefaultNodeSize)
 }
 
 // Result is a timezone lookup result
 type Result struct {
 	Name        string
+	ZoneID      uint32 // ID of Name in Zones, or NoZone when Name is not in the zone table
 	Coordinates geo.LatLng
 	Elapsed     time.Duration
+	Distance    float64 // Distance in meters to the nearest polygon when Approximate
+	Approximate bool    // True when found by the nearest timezone fallback
+	OnEdge      bool    // True when Coordinates are exactly on the border of the timezone's polygons
+	Source      Source  // Source of the timezone
 }
 
-func (tzc *Timezonecache) Save(filename string) error {
-	f2, err := os.OpenFile(filename, os.O_CREATE|os.O_RDWR, os.FileMode(0666))
+// Save writes the timezone data to filename. The data is written to a temporary file
+// that replaces filename once it is complete, so that processes that have loaded
+// filename are not affected.
+func (tzc *Timezonecache) Save(filename string) (err error) {
+	tzc.mu.Lock()
+	defer tzc.mu.Unlock()
+	if tzc.state == stateClosed {
+		return ErrClosed
+	}
+	f2, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
 	if err != nil {
 		return err
 	}
-	defer f2.Close()
+	defer func() {
+		if err != nil {
+			f2.Close()
+			os.Remove(f2.Name())
+		}
+	}()
+	if err = f2.Chmod(0644); err != nil {
+		return err
+	}
+	if err = tzc.write(f2); err != nil {
+		return err
+	}
+	if err = f2.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f2.Name(), filename)
+}
+
+// write writes the header, items, polygon data and packed RTree to w
+func (tzc *Timezonecache) write(w io.Writer) (err error) {
+	if len(tzc.release) > math.MaxUint16 {
+		return fmt.Errorf("%w: release is longer than %d bytes", ErrTooLarge, math.MaxUint16)
+	}
+	if int64(len(tzc.arr)) > math.MaxUint32 {
+		return fmt.Errorf("%w: more than %d polygons", ErrTooLarge, uint32(math.MaxUint32))
+	}
+	if tzc.dataSize > math.MaxUint32 {
+		return fmt.Errorf("%w: polygon data is longer than %d bytes", ErrTooLarge, uint32(math.MaxUint32))
+	}
+	headerLength := int64(headerSize + len(tzc.release) + itemSize*len(tzc.arr))
+	for _, name := range tzc.zones {
+		headerLength += int64(uvarintLen(uint64(len(name))) + len(name))
+	}
+	if headerLength > math.MaxUint32 {
+		return fmt.Errorf("%w: header is longer than %d bytes", ErrTooLarge, uint32(math.MaxUint32))
+	}
+	data := tzc.polygonData()
+	tree := tzc.packRtree()
+	if int64(len(tree)) > math.MaxUint32 {
+		return fmt.Errorf("%w: rtree is longer than %d bytes", ErrTooLarge, uint32(math.MaxUint32))
+	}
+	tzc.treeLength = uint32(len(tree))
+	tzc.created = time.Now().UTC().Truncate(time.Second)
+	tzc.checksum = crc32.Update(crc32.ChecksumIEEE(data), crc32.IEEETable, tree)
 
-	bw := bufio.NewWriter(f2)
-	buf := make([]byte, 256)
-	var n, written int
+	bw := bufio.NewWriter(w)
+	buf := make([]byte, headerSize+len(tzc.release))
 
-	if n, err = bw.Write(tzc.encodeHeader(buf)); err != nil {
+	if _, err = bw.Write(tzc.encodeHeader(buf, uint32(headerLength))); err != nil {
 		return err
 	}
-	written += n
-	for i := 0; i < len(tzc.name); i++ {
-		if n, err = bw.Write(tzc.encodeItem(buf, i)); err != nil {
+	for _, name := range tzc.zones {
+		if _, err = bw.Write(encodeName(buf, name)); err != nil {
+			return err
+		}
+	}
+	for i := range tzc.arr {
+		if _, err = bw.Write(tzc.encodeItem(buf, i)); err != nil {
 			return err
 		}
-		written += n
 	}
-	if err = bw.Flush(); err != nil {
+	if _, err = bw.Write(data); err != nil {
 		return err
 	}
-
-	if n, err = f2.WriteAt(tzc.data, int64(written)); err != nil {
+	if _, err = bw.Write(tree); err != nil {
 		return err
 	}
-	written += n
 	return bw.Flush()
 }
 
-func (tzc *Timezonecache) encodeItem(buf []byte, i int) []byte {
-	name := tzc.name[i]
-	if len(buf) >= 5+len(name) {
-		endian.PutUint32(buf, tzc.arr[i])
-		buf[4] = uint8(len(name))
-		copy(buf[5:5+len(name)], name)
+// polygonData returns the encoded polygons
+func (tzc *Timezonecache) polygonData() []byte {
+	if len(tzc.arr) == 0 {
+		return nil
 	}
-	return buf[:5+len(name)]
+	return tzc.data[tzc.bufOffset : tzc.bufOffset+tzc.dataSize]
 }
 
-func (tzc *Timezonecache) encodeHeader(b []byte) []byte {
-	headerLength := 10
-	if len(b) >= 10 {
-		for i := 0; i < len(tzc.name); i++ {
-			headerLength += 5 + len(tzc.name)
-		}
-		endian.PutUint32(b[:4], uint32(headerLength))
-		endian.PutUint32(b[4:8], uint32(len(tzc.data)))
-		endian.PutUint16(b[8:10], uint16(len(tzc.arr)))
+// encodeName encodes a name of the name table: [varint]length [...]name
+func encodeName(buf []byte, name string) []byte {
+	if len(buf) < binary.MaxVarintLen64+len(name) {
+		buf = make([]byte, binary.MaxVarintLen64+len(name))
 	}
+	n := binary.PutUvarint(buf, uint64(len(name)))
+	n += copy(buf[n:], name)
+	return buf[:n]
+}
 
-	return b[:10]
+// encodeItem encodes the item of polygon i: [4]offset [4]zone ID
+func (tzc *Timezonecache) encodeItem(buf []byte, i int) []byte {
+	endian.PutUint32(buf[0:4], tzc.arr[i])
+	endian.PutUint32(buf[4:8], tzc.zone[i])
+	return buf[:itemSize]
 }
 
-func (tzc *Timezonecache) decodeHeader(b []byte) (n int, err error) {
-	if len(b) > 10 {
-		tzc.dataOffset = endian.Uint32(b[:4])
-		tzc.dataLength = endian.Uint32(b[4:8])
-		items := endian.Uint16(b[8:10])
-		tzc.arr = make([]uint32, 0, items)
-		tzc.name = make([]string, 0, items)
-		return 10, nil
+func uvarintLen(x uint64) int {
+	n := 1
+	for ; x >= 0x80; x >>= 7 {
+		n++
 	}
-
-	return 0, errors.New("error []byte insufficient for header")
+	return n
 }
 
-func (tzc *Timezonecache) decodeItem(b []byte) (n int, err error) {
-	if len(b) > 4 && len(b) >= (5+int(b[4])) {
-		tzc.arr = append(tzc.arr, endian.Uint32(b[:4]))
-		tzc.name = append(tzc.name, string(b[5:5+b[4]]))
-		return (5 + int(b[4])), nil
-	}
-	return 0, errors.New("error []byte insufficient for an item")
+// encodeHeader encodes the header into b. b must be at least headerSize+len(release).
+func (tzc *Timezonecache) encodeHeader(b []byte, headerLength uint32) []byte {
+	copy(b[:8], headerMagic)
+	endian.PutUint16(b[8:10], formatVersion)
+	endian.PutUint16(b[10:12], uint16(len(tzc.release)))
+	endian.PutUint64(b[12:20], uint64(tzc.created.Unix()))
+	endian.PutUint32(b[20:24], tzc.checksum)
+	endian.PutUint32(b[24:28], headerLength)
+	endian.PutUint32(b[28:32], uint32(tzc.dataSize))
+	endian.PutUint32(b[32:36], uint32(len(tzc.arr)))
+	endian.PutUint32(b[36:40], uint32(len(tzc.zones)))
+	endian.PutUint32(b[40:44], tzc.treeLength)
+	endian.PutUint16(b[44:46], uint16(tzc.precision))
+	endian.PutUint16(b[46:48], uint16(tzc.codec))
+	copy(b[headerSize:], tzc.release)
+	return b[:headerSize+len(tzc.release)]
 }
 
-func (tzc *Timezonecache) Load(f *os.File) (err error) {
-	var d, discarded int
-	var b []byte
-	br := bufio.NewReader(f)
-	if b, err = br.Peek(256); err != nil {
-		return err
+// decodeHeader decodes the fixed length header from b and returns the length of
+// the release that follows it, and the number of polygons and names.
+func (tzc *Timezonecache) decodeHeader(b []byte) (release, polygons, names int, err error) {
+	if len(b) < 10 {
+		return 0, 0, 0, ErrTruncated
 	}
-	if d, err = tzc.decodeHeader(b); err != nil {
-		return err
+	if string(b[:8]) != headerMagic {
+		return 0, 0, 0, ErrInvalidMagic
 	}
-	if d, err = br.Discard(d); err != nil {
-		return err
+	if v := endian.Uint16(b[8:10]); v != formatVersion {
+		return 0, 0, 0, fmt.Errorf("%w: %d", ErrUnsupportedVersion, v)
 	}
-	discarded += d
-	for i := 0; i < cap(tzc.name); i++ {
-		if b, err = br.Peek(256); err != nil {
-			return err
+	if len(b) < headerSize {
+		return 0, 0, 0, ErrTruncated
+	}
+	release = int(endian.Uint16(b[10:12]))
+	tzc.created = time.Unix(int64(endian.Uint64(b[12:20])), 0).UTC()
+	tzc.checksum = endian.Uint32(b[20:24])
+	tzc.dataOffset = endian.Uint32(b[24:28])
+	tzc.dataLength = endian.Uint32(b[28:32])
+	polygons = int(endian.Uint32(b[32:36]))
+	names = int(endian.Uint32(b[36:40]))
+	tzc.treeLength = endian.Uint32(b[40:44])
+	if prec := endian.Uint16(b[44:46]); prec > uint16(geo.MicroDegrees) {
+		return 0, 0, 0, fmt.Errorf("%w: unknown precision %d", ErrCorrupt, prec)
+	}
+	tzc.precision = geo.Precision(endian.Uint16(b[44:46]))
+	if codec := endian.Uint16(b[46:48]); codec > uint16(geo.Delta) {
+		return 0, 0, 0, fmt.Errorf("%w: unknown codec %d", ErrCorrupt, codec)
+	}
+	tzc.codec = geo.Codec(endian.Uint16(b[46:48]))
+	tzc.dataSize = int64(tzc.dataLength)
+	return release, polygons, names, nil
+}
+
+// decodeZones decodes the zone table of length names from b and returns the
+// number of bytes read.
+func (tzc *Timezonecache) decodeZones(b []byte, names int) (int, error) {
+	// every name is at least one byte long
+	if names > len(b) {
+		return 0, ErrTruncated
+	}
+	tzc.zones = make([]string, names)
+	tzc.zoneIDs = make(map[string]uint32, names)
+	offset := 0
+	for i := range tzc.zones {
+		l, n := binary.Uvarint(b[offset:])
+		if n == 0 {
+			return 0, ErrTruncated
 		}
-		if d, err = tzc.decodeItem(b); err != nil {
-			return err
+		if n < 0 {
+			return 0, fmt.Errorf("%w: name %d has an invalid length", ErrCorrupt, i)
 		}
-		if d, err = br.Discard(d); err != nil {
-			return err
+		offset += n
+		if l > uint64(len(b)-offset) {
+			return 0, ErrTruncated
 		}
-		discarded += d
-	}
-	tzc.bufOffset = int64(discarded)
-	if tzc.data, err = mmap(f, 0, int64(tzc.dataLength+uint32(tzc.bufOffset))); err != nil {
-		return err
+		name := string(b[offset : offset+int(l)])
+		if _, ok := tzc.zoneIDs[name]; ok {
+			return 0, fmt.Errorf("%w: duplicate zone %q", ErrCorrupt, name)
+		}
+		tzc.zones[i], tzc.zoneIDs[name] = name, uint32(i)
+		offset += int(l)
 	}
-	tzc.BuildRtree()
-	return err
+	return offset, nil
 }
 
-func (tzc *Timezonecache) Close() error {
-	if tzc.data != nil {
-		err := munmap(tzc.data)
-		tzc.data = nil
-		return err
+// decodeItems decodes the offsets and zone IDs of polygons from b. The zone table must be decoded first.
+func (tzc *Timezonecache) decodeItems(b []byte, polygons int) error {
+	if len(b)/itemSize < polygons {
+		return ErrTruncated
+	}
+	tzc.arr = make([]uint32, polygons)
+	tzc.zone = make([]uint32, polygons)
+	var prev uint32
+	for i := 0; i < polygons; i++ {
+		item := b[itemSize*i : itemSize*(i+1)]
+		offset, zone := endian.Uint32(item[0:4]), endian.Uint32(item[4:8])
+		if offset < prev || offset > tzc.dataLength {
+			return fmt.Errorf("%w: polygon %d has an invalid offset %d", ErrCorrupt, i, offset)
+		}
+		if zone >= uint32(len(tzc.zones)) {
+			return fmt.Errorf("%w: polygon %d has an invalid zone ID %d", ErrCorrupt, i, zone)
+		}
+		tzc.arr[i], tzc.zone[i] = offset, zone
+		prev = offset
 	}
-	return errors.New("error timezone data is nil")
+	return nil
 }
 
+// BuildRtree inserts every polygon into the in-memory RTree. Load does not need
+// it since the packed RTree is read directly from the timezone data.
 func (tzc *Timezonecache) BuildRtree() {
+	tzc.mu.Lock()
+	defer tzc.mu.Unlock()
 	for i, _ := range tzc.arr {
 		id := uint(i)
-		p := geo.NewPolygonFromBytes(tzc.buf(id))
+		p := tzc.codec.DecodePolygon(tzc.buf(id), tzc.precision)
 		tzc.rt.InsertPolygon(p, id)
 	}
 }
 
-func mmap(f *os.File, offset, length int64) (mmapgo.MMap, error) {
-	if f == nil {
-		return nil, errors.New("error file not open")
-	}
-
-	return mmapgo.MapRegion(f, int(length), mmapgo.RDONLY, 0, offset)
-}
-
-func munmap(data mmapgo.MMap) (err error) {
-	if data != nil {
-		err = data.Unmap()
-		data = nil
-		return
-	}
-	return errors.New("error munmap data is nil")
-}
-
-// [8]items [8*items]offset [1*items]offsetstring
-
-// [4]dataoffset [2]items [4]offset [1]stringlength [...]string ... []
+// [8]magic [2]version [2]releaselength [8]created [4]checksum [4]dataoffset [4]datalength [4]polygons [4]names [4]treelength [2]precision [2]codec [...]release
+// [varint]namelength [...]name ... [4]offset [4]zoneid ... []data []tree
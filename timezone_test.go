@@ -0,0 +1,222 @@
+package timezoneLookup
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+)
+
+// square returns a Polygon of the square with the bottom-left corner at lat, lng
+func square(lat, lng, size float64) geo.Polygon {
+	return geo.NewPolygonFromVertices([]geo.LatLng{
+		{Lat: lat, Lng: lng},
+		{Lat: lat, Lng: lng + size},
+		{Lat: lat + size, Lng: lng + size},
+		{Lat: lat + size, Lng: lng},
+		{Lat: lat, Lng: lng},
+	})
+}
+
+// newTestCache returns a Timezonecache with the timezones A and B side by side
+// and C apart from them.
+func newTestCache() *Timezonecache {
+	tzc := new(Timezonecache)
+	tzc.AddTimezone(Timezone{Name: "A", Polygons: []geo.Polygon{square(0, 0, 10)}})
+	tzc.AddTimezone(Timezone{Name: "B", Polygons: []geo.Polygon{square(0, 10, 10)}})
+	tzc.AddTimezone(Timezone{Name: "C", Polygons: []geo.Polygon{square(20, 20, 5)}})
+	return tzc
+}
+
+// saveFile saves tzc to a temporary file and returns its name
+func saveFile(t testing.TB, tzc *Timezonecache) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "timezone.data")
+	if err := tzc.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+// loadFile loads the timezone data of filename memory mapped
+func loadFile(t testing.TB, filename string) *Timezonecache {
+	t.Helper()
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	tzc := new(Timezonecache)
+	if err = tzc.Load(f); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tzc.Close() })
+	return tzc
+}
+
+// expectSearch fails the test when the timezone at lat, lng is not name
+func expectSearch(t testing.TB, tzc *Timezonecache, lat, lng float64, name string) {
+	t.Helper()
+	res, err := tzc.Search(lat, lng)
+	if err != nil {
+		t.Fatalf("Search(%v, %v): %v", lat, lng, err)
+	}
+	if res.Name != name {
+		t.Fatalf("Search(%v, %v) = %q, want %q", lat, lng, res.Name, name)
+	}
+}
+
+func TestAddTimezoneAfterLoad(t *testing.T) {
+	filename := saveFile(t, newTestCache())
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := append([]byte(nil), b...)
+	bytesCache := new(Timezonecache)
+	if err = bytesCache.LoadBytes(b); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tzc := range map[string]*Timezonecache{"Load": loadFile(t, filename), "LoadBytes": bytesCache} {
+		t.Run(name, func(t *testing.T) {
+			tzc.AddTimezone(Timezone{Name: "D", Polygons: []geo.Polygon{square(40, 40, 5)}})
+			expectSearch(t, tzc, 42, 42, "D")
+			expectSearch(t, tzc, 5, 5, "A")
+			expectSearch(t, tzc, 5, 15, "B")
+			zg, err := tzc.Zone("D")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(zg.Polygons) != 1 || zg.Vertices != 5 || zg.Min != geo.NewLatLng(40, 40) {
+				t.Fatalf("Zone(D) = %d polygons, %d vertices, min %v", len(zg.Polygons), zg.Vertices, zg.Min)
+			}
+
+			saved := loadFile(t, saveFile(t, tzc))
+			expectSearch(t, saved, 42, 42, "D")
+			expectSearch(t, saved, 22, 22, "C")
+			if err := tzc.Close(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+	if !bytes.Equal(b, orig) {
+		t.Fatal("LoadBytes data was modified")
+	}
+}
+
+// gridPoints returns n random points around the Grid timezones of the test data
+func gridPoints(n int) []geo.LatLng {
+	r := rand.New(rand.NewSource(1))
+	points := make([]geo.LatLng, n)
+	for i := range points {
+		points[i] = geo.NewLatLng(46.5+r.Float64()*4, 6.5+r.Float64()*4)
+	}
+	return points
+}
+
+func TestSetCodecAfterLoad(t *testing.T) {
+	for prec := geo.Float32; prec.Valid(); prec++ {
+		raw := loadFile(t, saveFile(t, importTestdata(t, nil, prec, geo.Raw)))
+		tzc := loadFile(t, saveFile(t, importTestdata(t, nil, prec, geo.Raw)))
+		if err := tzc.SetCodec(geo.Delta); err != nil {
+			t.Fatal(err)
+		}
+		delta := loadFile(t, saveFile(t, tzc))
+		if info := delta.Info(); info.Codec != geo.Delta || info.Precision != prec {
+			t.Fatalf("%v: saved with codec %v and precision %v", prec, info.Codec, info.Precision)
+		}
+		for _, ll := range append(gridPoints(1000), geo.NewLatLng(-17, 179.99), geo.NewLatLng(-17, -179.99)) {
+			want, wantErr := raw.Search(ll.Lat, ll.Lng)
+			for _, c := range []*Timezonecache{tzc, delta} {
+				res, err := c.Search(ll.Lat, ll.Lng)
+				if err != wantErr || res.Name != want.Name || res.ZoneID != want.ZoneID || res.OnEdge != want.OnEdge {
+					t.Fatalf("%v: Search(%v) = %q, %v, want %q, %v", prec, ll, res.Name, err, want.Name, wantErr)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkSearchRaw(b *testing.B) {
+	benchmarkSearch(b, geo.Raw)
+}
+
+func BenchmarkSearchDelta(b *testing.B) {
+	benchmarkSearch(b, geo.Delta)
+}
+
+// benchmarkSearch benchmarks Search of the test data saved with codec and
+// loaded, and reports the size of the file.
+func benchmarkSearch(b *testing.B, codec geo.Codec) {
+	filename := saveFile(b, importTestdata(b, nil, geo.Float32, codec))
+	fi, err := os.Stat(filename)
+	if err != nil {
+		b.Fatal(err)
+	}
+	tzc := loadFile(b, filename)
+	points := gridPoints(1 << 12)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ll := points[i%len(points)]
+		tzc.Search(ll.Lat, ll.Lng)
+	}
+	b.ReportMetric(float64(fi.Size()), "bytes")
+}
+
+// TestSaveLarge tests more polygons and zones than fit in 16 bits and names and
+// a release longer than 255 bytes.
+func TestSaveLarge(t *testing.T) {
+	const polygons = 70001
+	long := strings.Repeat("Long/Name", 34)[:300]
+	name := func(i int) string {
+		if i == polygons-1 {
+			return long
+		}
+		return fmt.Sprintf("Zone/%d", i)
+	}
+	// squares of 0.4° every 0.5°
+	at := func(i int) (lat, lng float64) {
+		return float64(i/300)*0.5 - 80, float64(i%300)*0.5 - 170
+	}
+	tzc := new(Timezonecache)
+	tzc.SetRelease(strings.Repeat("r", 300))
+	for i := 0; i < polygons; i++ {
+		lat, lng := at(i)
+		tzc.AddTimezone(Timezone{Name: name(i), Polygons: []geo.Polygon{square(lat, lng, 0.4)}})
+	}
+	loaded := loadFile(t, saveFile(t, tzc))
+	info := loaded.Info()
+	if info.Polygons != polygons || info.Zones != polygons || info.Release != strings.Repeat("r", 300) {
+		t.Fatalf("loaded %d polygons, %d zones and a release of %d bytes", info.Polygons, info.Zones, len(info.Release))
+	}
+	for _, i := range []int{0, 1 << 16, polygons - 1} {
+		lat, lng := at(i)
+		expectSearch(t, loaded, lat+0.2, lng+0.2, name(i))
+	}
+	if id, ok := loaded.ZoneID(long); !ok || id != polygons-1 {
+		t.Fatalf("ZoneID(%q) = %d, %v", long, id, ok)
+	}
+}
+
+func TestSaveTooLarge(t *testing.T) {
+	tzc := newTestCache()
+	tzc.SetRelease(strings.Repeat("r", 1<<16))
+	filename := filepath.Join(t.TempDir(), "timezone.data")
+	if err := tzc.Save(filename); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("Save = %v, want %v", err, ErrTooLarge)
+	}
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Fatalf("Save created %s: %v", filename, err)
+	}
+	if entries, err := os.ReadDir(filepath.Dir(filename)); err != nil || len(entries) != 0 {
+		t.Fatalf("Save left %d files: %v", len(entries), err)
+	}
+}
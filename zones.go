@@ -0,0 +1,106 @@
+package timezoneLookup
+
+import (
+	"errors"
+	"math"
+
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+)
+
+var (
+	ErrUnknownZone = errors.New("error timezone is not in the zone table")
+)
+
+// NoZone is the zone ID of a Result with a timezone that is not in the zone table,
+// ex: a nautical timezone or no timezone at all.
+const NoZone uint32 = math.MaxUint32
+
+// Zones returns the unique timezone names of the Timezonecache indexed by zone ID.
+// Zone IDs are assigned in the order that timezones are added and are kept by Save
+// and Load, but differ between timezone databases.
+func (tzc *Timezonecache) Zones() []string {
+	tzc.mu.RLock()
+	defer tzc.mu.RUnlock()
+	return append([]string(nil), tzc.zones...)
+}
+
+// ZoneID returns the zone ID of the timezone name. Returns NoZone and false when
+// the timezone is not in the zone table.
+func (tzc *Timezonecache) ZoneID(name string) (uint32, bool) {
+	tzc.mu.RLock()
+	defer tzc.mu.RUnlock()
+	return tzc.zoneID(name)
+}
+
+func (tzc *Timezonecache) zoneID(name string) (uint32, bool) {
+	if id, ok := tzc.zoneIDs[name]; ok {
+		return id, true
+	}
+	return NoZone, false
+}
+
+// addZone returns the zone ID of the timezone name and adds it to the zone table when it is new.
+func (tzc *Timezonecache) addZone(name string) uint32 {
+	if id, ok := tzc.zoneIDs[name]; ok {
+		return id
+	}
+	if tzc.zoneIDs == nil {
+		tzc.zoneIDs = make(map[string]uint32)
+	}
+	id := uint32(len(tzc.zones))
+	tzc.zones = append(tzc.zones, name)
+	tzc.zoneIDs[name] = id
+	return id
+}
+
+// polygonZone returns the timezone name of polygon id
+func (tzc *Timezonecache) polygonZone(id uint) string {
+	return tzc.zones[tzc.zone[id]]
+}
+
+// ZoneGeometry is the geometry of a timezone.
+//
+// Polygons that cross the antimeridian are split at ±180° on import, so the
+// bounding box of a timezone on both sides of it, ex: Pacific/Fiji, spans
+// longitudes -180° to 180°. The Min and Max of its polygons are the bounds of
+// each side.
+type ZoneGeometry struct {
+	Name         string
+	ID           uint32        // Zone ID
+	Polygons     []geo.Polygon // Polygons of the timezone
+	PolygonCount int           // Number of polygons
+	Min, Max     geo.LatLng    // Bounding box of all polygons
+	Vertices     int           // Number of vertices of all polygons, including holes
+	Area         float64       // Approximate geodesic area in square meters
+}
+
+// Zone returns the geometry of the timezone name. The polygons are copies and
+// remain valid after the Timezonecache is closed. Returns ErrUnknownZone when
+// the timezone is not in the zone table.
+func (tzc *Timezonecache) Zone(name string) (ZoneGeometry, error) {
+	if err := tzc.rlock(); err != nil {
+		return ZoneGeometry{}, err
+	}
+	defer tzc.mu.RUnlock()
+	zone, ok := tzc.zoneID(name)
+	if !ok {
+		return ZoneGeometry{}, ErrUnknownZone
+	}
+	zg := ZoneGeometry{Name: name, ID: zone}
+	for id, z := range tzc.zone {
+		if z != zone {
+			continue
+		}
+		p := tzc.codec.DecodePolygon(tzc.buf(uint(id)), tzc.precision)
+		if len(zg.Polygons) == 0 {
+			zg.Min, zg.Max = p.Min(), p.Max()
+		} else {
+			zg.Min, zg.Max = geo.ExpandBounds(zg.Min, zg.Max, p.Min(), p.Max())
+		}
+		zg.Vertices += p.Length()
+		zg.Area += p.Area()
+		zg.Polygons = append(zg.Polygons, p)
+	}
+	zg.PolygonCount = len(zg.Polygons)
+	return zg, nil
+}
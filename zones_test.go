@@ -0,0 +1,111 @@
+package timezoneLookup
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/evanoberholster/timezoneLookup/v2/geo"
+)
+
+func TestResultZoneID(t *testing.T) {
+	tzc := newTestCache()
+	// Z overlaps A
+	tzc.AddTimezone(Timezone{Name: "Z", Polygons: []geo.Polygon{square(2, 2, 2)}})
+	tzc.SetTieBreak(TieBreakError)
+
+	points := []geo.LatLng{
+		geo.NewLatLng(5, 15),  // B
+		geo.NewLatLng(95, 5),  // invalid
+		geo.NewLatLng(3, 3),   // A and Z
+		geo.NewLatLng(-5, -5), // no timezone
+		geo.NewLatLng(5, 5),   // A
+	}
+	want := []struct {
+		name string
+		err  error
+	}{{"B", nil}, {"", ErrCoordinatesNotValid}, {"", ErrAmbiguousTimezone}, {"", nil}, {"A", nil}}
+
+	out := make([]Result, len(points))
+	if err := tzc.SearchBatch(points, out); !errors.Is(err, ErrCoordinatesNotValid) {
+		t.Fatalf("SearchBatch: %v", err)
+	}
+	for i, ll := range points {
+		res, err := tzc.Search(ll.Lat, ll.Lng)
+		if !errors.Is(err, want[i].err) {
+			t.Fatalf("Search(%v): %v, want %v", ll, err, want[i].err)
+		}
+		for _, res := range []Result{res, out[i]} {
+			zone, ok := tzc.ZoneID(want[i].name)
+			if !ok {
+				zone = NoZone
+			}
+			if res.Name != want[i].name || res.ZoneID != zone {
+				t.Fatalf("Search(%v) = %q zone ID %d, want %q zone ID %d", ll, res.Name, res.ZoneID, want[i].name, zone)
+			}
+		}
+	}
+
+	tzc.Close()
+	if res, err := tzc.Search(5, 5); !errors.Is(err, ErrClosed) || res.ZoneID != NoZone {
+		t.Fatalf("Search after Close = zone ID %d, %v", res.ZoneID, err)
+	}
+	in := make(chan geo.LatLng, 1)
+	in <- geo.NewLatLng(5, 5)
+	close(in)
+	for r := range tzc.SearchStream(in) {
+		if !errors.Is(r.Err, ErrClosed) || r.ZoneID != NoZone {
+			t.Fatalf("SearchStream after Close = zone ID %d, %v", r.ZoneID, r.Err)
+		}
+	}
+}
+
+func TestZone(t *testing.T) {
+	tzc := newTestCache()
+	tzc.AddTimezone(Timezone{Name: "A", Polygons: []geo.Polygon{square(-10, 0, 5)}})
+	zg, err := tzc.Zone("A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zg.Name != "A" || zg.PolygonCount != 2 || len(zg.Polygons) != 2 || zg.Vertices != 10 {
+		t.Fatalf("Zone(A) = %q, %d polygons, %d vertices", zg.Name, zg.PolygonCount, zg.Vertices)
+	}
+	if zg.Min != geo.NewLatLng(-10, 0) || zg.Max != geo.NewLatLng(10, 10) {
+		t.Fatalf("Zone(A) bounds %v %v", zg.Min, zg.Max)
+	}
+	// 10° by 10° at the equator and 5° by 5° at 10°S are about 1.53e12 m²
+	if zg.Area < 1.4e12 || zg.Area > 1.7e12 {
+		t.Fatalf("Zone(A) area %v", zg.Area)
+	}
+	if _, err := tzc.Zone("Unknown"); !errors.Is(err, ErrUnknownZone) {
+		t.Fatalf("Zone(Unknown): %v", err)
+	}
+}
+
+func TestZoneAntimeridian(t *testing.T) {
+	tzc := new(Timezonecache)
+	fiji := `{"type":"FeatureCollection","features":[{"type":"Feature","properties":{"tzid":"Pacific/Fiji"},
+		"geometry":{"type":"Polygon","coordinates":[[[178,-18],[-179,-18],[-179,-16],[178,-16],[178,-18]]]}}]}`
+	if err := ImportGeoJSON(strings.NewReader(fiji), func(tz Timezone) error {
+		tzc.AddTimezone(tz)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	zg, err := tzc.Zone("Pacific/Fiji")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zg.PolygonCount != 2 || zg.Min.Lng != -180 || zg.Max.Lng != 180 {
+		t.Fatalf("Zone(Pacific/Fiji) = %d polygons, bounds %v %v", zg.PolygonCount, zg.Min, zg.Max)
+	}
+	// the bounds of each side
+	west, east := zg.Polygons[0], zg.Polygons[1]
+	if west.Min().Lng > east.Min().Lng {
+		west, east = east, west
+	}
+	if west.Min() != geo.NewLatLng(-18, -180) || west.Max() != geo.NewLatLng(-16, -179) ||
+		east.Min() != geo.NewLatLng(-18, 178) || east.Max() != geo.NewLatLng(-16, 180) {
+		t.Fatalf("sides %v %v and %v %v", west.Min(), west.Max(), east.Min(), east.Max())
+	}
+}